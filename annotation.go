@@ -0,0 +1,446 @@
+package glot
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Coordinate is a value in one of gnuplot's coordinate systems: "first"
+// (the default) and "second" are the units of the primary and secondary
+// axes, "graph" goes from 0 to 1 across the plot area, "screen" from 0
+// to 1 across the whole image and "character" counts characters.
+type Coordinate struct {
+	Value  float64
+	System string
+}
+
+var coordinateSystems = []string{"first", "second", "graph", "screen", "character"}
+
+func (c Coordinate) check() error {
+	if c.System != "" && !slices.Contains(coordinateSystems, c.System) {
+		return &gnuplotError{fmt.Sprintf("invalid coordinate system '%s', expected one of %v", c.System, coordinateSystems)}
+	}
+	return nil
+}
+
+func (c Coordinate) String() string {
+	if c.System == "" {
+		return formatValue(c.Value)
+	}
+	return c.System + " " + formatValue(c.Value)
+}
+
+// Position is a point of the plot. Its coordinates can use different
+// systems, e.g. a time on the x-axis and the top of the plot area:
+//
+//	glot.Position{X: glot.Coordinate{Value: t}, Y: glot.Coordinate{Value: 1, System: "graph"}}
+type Position struct {
+	X, Y Coordinate
+}
+
+// First returns the position at x and y in the units of the primary
+// axes.
+func First(x, y float64) Position {
+	return Position{Coordinate{x, "first"}, Coordinate{y, "first"}}
+}
+
+// Second returns the position at x and y in the units of the secondary
+// axes.
+func Second(x, y float64) Position {
+	return Position{Coordinate{x, "second"}, Coordinate{y, "second"}}
+}
+
+// Graph returns the position at x and y relative to the plot area, from
+// (0, 0) at the bottom left to (1, 1) at the top right.
+func Graph(x, y float64) Position {
+	return Position{Coordinate{x, "graph"}, Coordinate{y, "graph"}}
+}
+
+// Screen returns the position at x and y relative to the whole image,
+// from (0, 0) at the bottom left to (1, 1) at the top right.
+func Screen(x, y float64) Position {
+	return Position{Coordinate{x, "screen"}, Coordinate{y, "screen"}}
+}
+
+// Character returns the position at x and y in character widths and
+// heights from the bottom left of the image.
+func Character(x, y float64) Position {
+	return Position{Coordinate{x, "character"}, Coordinate{y, "character"}}
+}
+
+func (p Position) check() error {
+	if err := p.X.check(); err != nil {
+		return err
+	}
+	return p.Y.check()
+}
+
+func (p Position) String() string {
+	return p.X.String() + ", " + p.Y.String()
+}
+
+// Annotation is a Label, Arrow, Rectangle, Circle, Ellipse or Polygon
+// drawn on a plot, see Plot.AddAnnotation.
+type Annotation interface {
+	// kind returns the gnuplot option of the annotation: "label",
+	// "arrow" or "object".
+	kind() string
+	// spec returns the arguments of `set <kind> <id>`.
+	spec() (string, error)
+}
+
+// Label is a text at a position of the plot. The LineColor of Style
+// colors the text. If Point is set, a point is drawn at the position
+// with the PointType and PointSize of Style.
+type Label struct {
+	Text   string
+	At     Position
+	Align  string  // "left" (default), "center" or "right"
+	Rotate float64 // counterclockwise rotation in degrees
+	Font   string  // e.g. "Helvetica,10"
+	Point  bool
+	Offset *Position // offset of the text from the point, usually in characters
+	Style  PlotObjectStyle
+}
+
+// Arrow is a line between two positions of the plot, with heads at the
+// ends given by Heads. The LineColor, LineWidth and DashType of Style
+// draw the line.
+type Arrow struct {
+	From, To Position
+	Heads    string // "head" (default), "backhead", "heads" or "nohead"
+	Style    PlotObjectStyle
+}
+
+// Rectangle is a rectangle with corners From and To, e.g. to shade a
+// time span of an incident. The LineColor of Style colors the
+// rectangle, LineWidth and DashType draw its border.
+type Rectangle struct {
+	From, To Position
+	Fill     string // fill style, e.g. "solid 0.3 noborder" or "empty"
+	Style    PlotObjectStyle
+}
+
+// Circle is a circle around Center, styled like a Rectangle.
+type Circle struct {
+	Center Position
+	Radius Coordinate // the radius is measured along the x-axis
+	Fill   string
+	Style  PlotObjectStyle
+}
+
+// Ellipse is an ellipse around Center with the given width and height,
+// rotated counterclockwise by Angle degrees and styled like a
+// Rectangle.
+type Ellipse struct {
+	Center Position
+	Size   Position
+	Angle  float64
+	Fill   string
+	Style  PlotObjectStyle
+}
+
+// Polygon is a closed polygon through at least three vertices, styled
+// like a Rectangle.
+type Polygon struct {
+	Vertices []Position
+	Fill     string
+	Style    PlotObjectStyle
+}
+
+func (Label) kind() string     { return "label" }
+func (Arrow) kind() string     { return "arrow" }
+func (Rectangle) kind() string { return "object" }
+func (Circle) kind() string    { return "object" }
+func (Ellipse) kind() string   { return "object" }
+func (Polygon) kind() string   { return "object" }
+
+func (l Label) spec() (string, error) {
+	if err := l.At.check(); err != nil {
+		return "", err
+	}
+	spec := []string{fmt.Sprintf("%q at %s", l.Text, l.At)}
+	if l.Align != "" {
+		if !slices.Contains([]string{"left", "center", "right"}, l.Align) {
+			return "", &gnuplotError{fmt.Sprintf("invalid label alignment '%s'", l.Align)}
+		}
+		spec = append(spec, l.Align)
+	}
+	if l.Rotate != 0 {
+		spec = append(spec, "rotate by "+formatValue(l.Rotate))
+	}
+	if l.Font != "" {
+		spec = append(spec, fmt.Sprintf("font %q", l.Font))
+	}
+	if l.Style.LineColor != nil {
+		spec = append(spec, colorSpec("tc", l.Style.LineColor))
+	}
+	if l.Point {
+		point := PlotObjectStyle{PointType: l.Style.PointType, PointSize: l.Style.PointSize}
+		spec = append(spec, strings.TrimSpace("point "+point.String()))
+	}
+	if l.Offset != nil {
+		if err := l.Offset.check(); err != nil {
+			return "", err
+		}
+		spec = append(spec, "offset "+l.Offset.String())
+	}
+	return strings.Join(spec, " "), nil
+}
+
+func (a Arrow) spec() (string, error) {
+	if err := a.From.check(); err != nil {
+		return "", err
+	}
+	if err := a.To.check(); err != nil {
+		return "", err
+	}
+	spec := []string{fmt.Sprintf("from %s to %s", a.From, a.To)}
+	if a.Heads != "" {
+		if !slices.Contains([]string{"head", "backhead", "heads", "nohead"}, a.Heads) {
+			return "", &gnuplotError{fmt.Sprintf("invalid arrow heads '%s'", a.Heads)}
+		}
+		spec = append(spec, a.Heads)
+	}
+	line := PlotObjectStyle{LineColor: a.Style.LineColor, LineWidth: a.Style.LineWidth, DashType: a.Style.DashType}
+	if s := line.String(); s != "" {
+		spec = append(spec, s)
+	}
+	return strings.Join(spec, " "), nil
+}
+
+func (r Rectangle) spec() (string, error) {
+	if err := r.From.check(); err != nil {
+		return "", err
+	}
+	if err := r.To.check(); err != nil {
+		return "", err
+	}
+	return objectSpec(fmt.Sprintf("rect from %s to %s", r.From, r.To), r.Fill, r.Style), nil
+}
+
+func (c Circle) spec() (string, error) {
+	if err := c.Center.check(); err != nil {
+		return "", err
+	}
+	if err := c.Radius.check(); err != nil {
+		return "", err
+	}
+	return objectSpec(fmt.Sprintf("circle at %s size %s", c.Center, c.Radius), c.Fill, c.Style), nil
+}
+
+func (e Ellipse) spec() (string, error) {
+	if err := e.Center.check(); err != nil {
+		return "", err
+	}
+	if err := e.Size.check(); err != nil {
+		return "", err
+	}
+	shape := fmt.Sprintf("ellipse at %s size %s", e.Center, e.Size)
+	if e.Angle != 0 {
+		shape += " angle " + formatValue(e.Angle)
+	}
+	return objectSpec(shape, e.Fill, e.Style), nil
+}
+
+func (p Polygon) spec() (string, error) {
+	if len(p.Vertices) < 3 {
+		return "", &gnuplotError{fmt.Sprintf("a polygon needs at least 3 vertices, got %d", len(p.Vertices))}
+	}
+	vertices := make([]string, len(p.Vertices), len(p.Vertices)+1)
+	for i, v := range p.Vertices {
+		if err := v.check(); err != nil {
+			return "", err
+		}
+		vertices[i] = v.String()
+	}
+	if p.Vertices[0] != p.Vertices[len(p.Vertices)-1] {
+		// gnuplot only fills closed polygons
+		vertices = append(vertices, vertices[0])
+	}
+	return objectSpec("polygon from "+strings.Join(vertices, " to "), p.Fill, p.Style), nil
+}
+
+// objectSpec adds the fill and the style to the shape of an object.
+func objectSpec(shape, fill string, style PlotObjectStyle) string {
+	spec := []string{shape}
+	if style.LineColor != nil {
+		spec = append(spec, colorSpec("fc", style.LineColor))
+	}
+	if fill != "" {
+		spec = append(spec, "fs "+fill)
+	}
+	border := PlotObjectStyle{LineWidth: style.LineWidth, DashType: style.DashType}
+	if s := border.String(); s != "" {
+		spec = append(spec, s)
+	}
+	return strings.Join(spec, " ")
+}
+
+// colorSpec returns a color of a PlotObjectStyle as the given gnuplot
+// option, e.g. "tc rgb \"red\"" for the text color.
+func colorSpec(option string, color *PlotObjectColor) string {
+	return fmt.Sprintf("%s %s \"%s\"", option, color.ColorSpec, color.Value)
+}
+
+// annotationSetting returns the setting that draws an annotation.
+func annotationSetting(id int, a Annotation) (setting, error) {
+	spec, err := a.spec()
+	if err != nil {
+		return setting{}, err
+	}
+	return setting{name: a.kind() + " " + strconv.Itoa(id), value: spec}, nil
+}
+
+// nextAnnotationID returns an ID that no label, arrow or object of the
+// plot uses yet, including those set with Cmd.
+func (plot *Plot) nextAnnotationID() int {
+	id := 1
+	for _, s := range plot.settings {
+		words := strings.Fields(s.name)
+		if len(words) != 2 || !slices.Contains([]string{"label", "arrow", "object"}, words[0]) {
+			continue
+		}
+		if n, err := strconv.Atoi(words[1]); err == nil && n >= id {
+			id = n + 1
+		}
+	}
+	for n := range plot.annotations {
+		if n >= id {
+			id = n + 1
+		}
+	}
+	return id
+}
+
+// AddAnnotation draws a label, arrow or shape on the plot and returns
+// its ID, which is unique among the annotations of the plot.
+//
+// Usage
+//
+//	id, _ := plot.AddAnnotation(glot.Arrow{
+//		From:  glot.Position{X: glot.Coordinate{Value: 42}, Y: glot.Coordinate{Value: 0, System: "graph"}},
+//		To:    glot.Position{X: glot.Coordinate{Value: 42}, Y: glot.Coordinate{Value: 1, System: "graph"}},
+//		Heads: "nohead",
+//		Style: *glot.NewPlotObjectStyle(glot.SetLineColor("rgb", "red"), glot.SetLineWidth(2)),
+//	})
+//	plot.AddAnnotation(glot.Label{Text: "incident", At: glot.Graph(0.05, 0.95)})
+func (plot *Plot) AddAnnotation(a Annotation) (int, error) {
+	id := plot.nextAnnotationID()
+	s, err := annotationSetting(id, a)
+	if err != nil {
+		return 0, err
+	}
+	if err := plot.apply(s); err != nil {
+		return 0, err
+	}
+	if plot.annotations == nil {
+		plot.annotations = make(map[int]Annotation)
+	}
+	plot.annotations[id] = a
+	return id, nil
+}
+
+// UpdateAnnotation replaces the annotation with the given ID by a, which
+// must be of the same kind: a label, an arrow or a shape.
+//
+// Usage
+//
+//	plot.UpdateAnnotation(id, glot.Label{Text: "resolved", At: glot.Graph(0.05, 0.95)})
+func (plot *Plot) UpdateAnnotation(id int, a Annotation) error {
+	prev, ok := plot.Annotation(id)
+	if !ok {
+		return &gnuplotError{fmt.Sprintf("An annotation with ID %d does not exist.", id)}
+	}
+	if prev.kind() != a.kind() {
+		return &gnuplotError{fmt.Sprintf("can't replace the %s %d by an annotation of kind %s", prev.kind(), id, a.kind())}
+	}
+	s, err := annotationSetting(id, a)
+	if err != nil {
+		return err
+	}
+	if err := plot.apply(s); err != nil {
+		return err
+	}
+	plot.annotations[id] = a
+	return nil
+}
+
+// RemoveAnnotation removes the annotation with the given ID from the
+// plot.
+func (plot *Plot) RemoveAnnotation(id int) error {
+	a, ok := plot.Annotation(id)
+	if !ok {
+		return &gnuplotError{fmt.Sprintf("An annotation with ID %d does not exist.", id)}
+	}
+	name := a.kind() + " " + strconv.Itoa(id)
+	if err := plot.removeSetting(name, "unset "+name); err != nil {
+		return err
+	}
+	delete(plot.annotations, id)
+	return nil
+}
+
+// Annotation returns the annotation with the given ID. Annotations
+// changed or removed with Cmd aren't returned.
+func (plot *Plot) Annotation(id int) (Annotation, bool) {
+	a, ok := plot.annotations[id]
+	if !ok {
+		return nil, false
+	}
+	want, err := annotationSetting(id, a)
+	if err != nil || !slices.Contains(plot.settings, want) {
+		return nil, false
+	}
+	return a, true
+}
+
+// Annotations iterates over the IDs and annotations of the plot in the
+// order of their IDs.
+//
+// Usage
+//
+//	for id, a := range plot.Annotations() {
+//		fmt.Println(id, a)
+//	}
+func (plot *Plot) Annotations() iter.Seq2[int, Annotation] {
+	var ids []int
+	for _, id := range slices.Sorted(maps.Keys(plot.annotations)) {
+		if _, ok := plot.Annotation(id); ok {
+			ids = append(ids, id)
+		}
+	}
+	return func(yield func(int, Annotation) bool) {
+		for _, id := range ids {
+			if !yield(id, plot.annotations[id]) {
+				return
+			}
+		}
+	}
+}
+
+// removeSetting drops a setting and redraws the plot, or sends undo if
+// the plot is empty.
+func (plot *Plot) removeSetting(name, undo string) error {
+	i := slices.IndexFunc(plot.settings, func(s setting) bool { return s.name == name })
+	if i < 0 {
+		return nil
+	}
+	prev := slices.Clone(plot.settings)
+	plot.settings = slices.Delete(plot.settings, i, i+1)
+	var err error
+	if len(plot.PointGroup) == 0 && plot.figure == nil {
+		err = plot.cmd(context.Background(), "%s", undo)
+	} else {
+		err = plot.Render()
+	}
+	if err != nil {
+		plot.settings = prev
+	}
+	return err
+}
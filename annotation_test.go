@@ -0,0 +1,105 @@
+package glot
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestAddAnnotation(t *testing.T) {
+	backend := NewRecordingBackend()
+	plot, _ := NewPlotWithOptions(2, WithBackend(backend))
+	plot.Cmd("set label 1 'manual' at 0, 0")
+	backend.Reset()
+	red := *NewPlotObjectStyle(SetLineColor("rgb", "red"), SetLineWidth(2), SetDashType(2))
+	incident := Position{X: Coordinate{Value: 10}, Y: Coordinate{Value: 0, System: "graph"}}
+	annotations := []Annotation{
+		Label{Text: "threshold", At: First(0, 95), Align: "right", Rotate: 90, Font: "Helvetica,10",
+			Point: true, Offset: &Position{X: Coordinate{1, "character"}}, Style: *NewPlotObjectStyle(SetLineColor("rgb", "red"), SetPointType(7))},
+		Arrow{From: incident, To: Position{X: incident.X, Y: Coordinate{1, "graph"}}, Heads: "nohead", Style: red},
+		Rectangle{From: incident, To: Position{X: Coordinate{20, "first"}, Y: Coordinate{1, "graph"}}, Fill: "solid 0.3 noborder", Style: red},
+		Circle{Center: Second(1, 2), Radius: Coordinate{0.1, "graph"}},
+		Ellipse{Center: Screen(0.5, 0.5), Size: Screen(0.2, 0.1), Angle: 30, Fill: "empty"},
+		Polygon{Vertices: []Position{Character(1, 1), Character(3, 1), Character(2, 2)}},
+	}
+	for i, a := range annotations {
+		id, err := plot.AddAnnotation(a)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id != i+2 {
+			t.Errorf("Expected ID %d, got %d", i+2, id)
+		}
+	}
+	want := []string{
+		"set label 2 \"threshold\" at first 0, first 95 right rotate by 90 font \"Helvetica,10\" tc rgb \"red\" point pt 7 offset character 1, 0",
+		"set arrow 3 from 10, graph 0 to 10, graph 1 nohead lc rgb \"red\" lw 2.000000 dt 2",
+		"set object 4 rect from 10, graph 0 to first 20, graph 1 fc rgb \"red\" fs solid 0.3 noborder lw 2.000000 dt 2",
+		"set object 5 circle at second 1, second 2 size graph 0.1",
+		"set object 6 ellipse at screen 0.5, screen 0.5 size screen 0.2, screen 0.1 angle 30 fs empty",
+		"set object 7 polygon from character 1, character 1 to character 3, character 1 to character 2, character 2 to character 1, character 1",
+	}
+	if got := backend.Commands(); !slices.Equal(got, want) {
+		t.Errorf("Wrong commands:\n got %q\nwant %q", got, want)
+	}
+
+	invalid := []Annotation{
+		Label{Text: "x", At: Position{X: Coordinate{1, "data"}}},
+		Label{Text: "x", Align: "middle"},
+		Arrow{Heads: "both"},
+		Polygon{Vertices: []Position{First(0, 0), First(1, 1)}},
+	}
+	for _, a := range invalid {
+		if _, err := plot.AddAnnotation(a); err == nil {
+			t.Errorf("Expected an error for the annotation %+v", a)
+		}
+	}
+}
+
+func TestUpdateAndRemoveAnnotation(t *testing.T) {
+	backend := NewRecordingBackend()
+	plot, _ := NewPlotWithOptions(2, WithBackend(backend), WithTransport(TransportDatablock))
+	label, _ := plot.AddAnnotation(Label{Text: "deploy", At: Graph(0.1, 0.9)})
+	arrow, _ := plot.AddAnnotation(Arrow{From: First(0, 0), To: First(1, 1)})
+	plot.AddPointGroup("p99", "lines", []float64{1, 2})
+	backend.Reset()
+
+	if err := plot.UpdateAnnotation(label, Label{Text: "rollback", At: Graph(0.1, 0.9)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := plot.UpdateAnnotation(arrow, Label{Text: "arrow"}); err == nil {
+		t.Error("Expected an error for changing the kind of an annotation")
+	}
+	if err := plot.RemoveAnnotation(arrow); err != nil {
+		t.Fatal(err)
+	}
+	if err := plot.RemoveAnnotation(arrow); err == nil {
+		t.Error("Expected an error for removing an annotation twice")
+	}
+	want := []string{
+		"reset",
+		"set label 1 \"rollback\" at graph 0.1, graph 0.9",
+		"set arrow 2 from first 0, first 0 to first 1, first 1",
+		"plot $G1 title \"p99\"  with lines",
+		"reset",
+		"set label 1 \"rollback\" at graph 0.1, graph 0.9",
+		"plot $G1 title \"p99\"  with lines",
+	}
+	if got := backend.Commands(); !slices.Equal(got, want) {
+		t.Errorf("Wrong commands:\n got %q\nwant %q", got, want)
+	}
+
+	var ids []int
+	for id, a := range plot.Annotations() {
+		ids = append(ids, id)
+		if a.(Label).Text != "rollback" {
+			t.Errorf("Expected the updated label, got %+v", a)
+		}
+	}
+	if !slices.Equal(ids, []int{label}) {
+		t.Errorf("Wrong annotations: got %v, want %v", ids, []int{label})
+	}
+	plot.Cmd("unset label 1")
+	if _, ok := plot.Annotation(label); ok {
+		t.Error("Expected a label unset with Cmd to be gone")
+	}
+}
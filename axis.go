@@ -0,0 +1,151 @@
+package glot
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Axis configures the range of an axis, see Plot.SetAxis. Ends without
+// a bound are autoscaled.
+type Axis struct {
+	Name     string   // "x", "y", "z", "x2", "y2" or "cb"
+	Min      *float64 // lower bound, nil to autoscale it
+	Max      *float64 // upper bound, nil to autoscale it
+	Reverse  bool     // draw the axis from Max to Min
+	NoExtend bool     // don't extend autoscaled ends to the next tic
+	Unset    bool     // go back to gnuplot's default range
+}
+
+// AxisOptions configures an Axis made with NewAxis.
+type AxisOptions func(*Axis)
+
+var axisNames = []string{"x", "y", "z", "x2", "y2", "cb"}
+
+// AxisBounds sets both ends of the range.
+func AxisBounds(min, max float64) AxisOptions {
+	return func(a *Axis) {
+		a.Min = &min
+		a.Max = &max
+	}
+}
+
+// AxisMin sets the lower end of the range, the upper end stays open.
+func AxisMin(min float64) AxisOptions {
+	return func(a *Axis) {
+		a.Min = &min
+	}
+}
+
+// AxisMax sets the upper end of the range, the lower end stays open.
+func AxisMax(max float64) AxisOptions {
+	return func(a *Axis) {
+		a.Max = &max
+	}
+}
+
+// AxisAutoscale autoscales both ends of the range.
+func AxisAutoscale() AxisOptions {
+	return func(a *Axis) {
+		a.Min = nil
+		a.Max = nil
+	}
+}
+
+// AxisReverse reverses the direction of the axis.
+func AxisReverse() AxisOptions {
+	return func(a *Axis) {
+		a.Reverse = true
+	}
+}
+
+// AxisNoExtend fixes autoscaled ends at the extremes of the data
+// instead of extending them to the next tic.
+func AxisNoExtend() AxisOptions {
+	return func(a *Axis) {
+		a.NoExtend = true
+	}
+}
+
+// AxisUnset goes back to gnuplot's default range, i.e. autoscaling.
+func AxisUnset() AxisOptions {
+	return func(a *Axis) {
+		a.Unset = true
+	}
+}
+
+// Contructor for an axis configuration with optional parameters
+//
+// Usage
+//
+//	plot.SetAxis(glot.NewAxis("x", glot.AxisBounds(0.001, 0.005)))
+//	plot.SetAxis(glot.NewAxis("y", glot.AxisMin(0), glot.AxisNoExtend()))
+//	plot.SetAxis(glot.NewAxis("y2", glot.AxisReverse()))
+func NewAxis(name string, options ...AxisOptions) *Axis {
+	a := &Axis{Name: name}
+	for _, option := range options {
+		option(a)
+	}
+	return a
+}
+
+// rangeSpec returns the arguments of `set <name>range`.
+func (a *Axis) rangeSpec() string {
+	bound := func(v *float64) string {
+		if v == nil {
+			return "*"
+		}
+		return formatValue(*v)
+	}
+	spec := fmt.Sprintf("[%s:%s]", bound(a.Min), bound(a.Max))
+	if a.Reverse {
+		spec += " reverse"
+	}
+	if a.NoExtend {
+		spec += " noextend"
+	}
+	return spec
+}
+
+// SetAxis configures the range of an axis. It works the same for 2D
+// and 3D plots, except that the secondary axes x2 and y2 are only
+// available in 2D plots.
+//
+// Usage
+//
+//	plot.SetAxis(glot.NewAxis("x", glot.AxisBounds(0.001, 0.005)))
+//	plot.SetAxis(glot.NewAxis("y", glot.AxisMin(0)))
+//	plot.SetAxis(glot.NewAxis("x", glot.AxisUnset()))
+func (plot *Plot) SetAxis(axis *Axis) error {
+	if !slices.Contains(axisNames, axis.Name) {
+		return &gnuplotError{fmt.Sprintf("invalid axis '%s', expected one of %v", axis.Name, axisNames)}
+	}
+	if strings.HasSuffix(axis.Name, "2") {
+		if err := plot.checkSecondaryAxes(); err != nil {
+			return err
+		}
+	}
+	name := axis.Name + "range"
+	if !axis.Unset {
+		return plot.set(name, axis.rangeSpec())
+	}
+	return plot.removeSetting(name, "set "+name+" [*:*] noreverse extend")
+}
+
+// SetOffsets adds space around the data of autoscaled x and y axes,
+// in the units of the axes. The ends are extended after the offsets
+// are applied, see AxisNoExtend.
+//
+// Usage
+//
+//	plot.SetOffsets(0, 0, 0.5, 0.5)
+func (plot *Plot) SetOffsets(left, right, top, bottom float64) error {
+	return plot.set("offsets", fmt.Sprintf("%s, %s, %s, %s", formatValue(left), formatValue(right),
+		formatValue(top), formatValue(bottom)))
+}
+
+// formatValue formats a value in its shortest exact representation.
+func formatValue(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,59 @@
+package glot
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSetAxis(t *testing.T) {
+	tests := []struct {
+		axis *Axis
+		want string
+	}{
+		{NewAxis("x", AxisBounds(0.001, 0.005)), "set xrange [0.001:0.005]"},
+		{NewAxis("y", AxisMin(0)), "set yrange [0:*]"},
+		{NewAxis("z", AxisMax(-1.5), AxisReverse()), "set zrange [*:-1.5] reverse"},
+		{NewAxis("cb", AxisAutoscale(), AxisNoExtend()), "set cbrange [*:*] noextend"},
+		{NewAxis("y2", AxisBounds(1, 2), AxisAutoscale()), "set y2range [*:*]"},
+	}
+	for _, test := range tests {
+		backend := NewRecordingBackend()
+		plot, _ := NewPlotWithOptions(2, WithBackend(backend), WithTerminal("dumb"))
+		backend.Reset()
+		if err := plot.SetAxis(test.axis); err != nil {
+			t.Fatal(err)
+		}
+		if got := backend.Commands(); !slices.Equal(got, []string{test.want}) {
+			t.Errorf("Wrong commands for %+v: got %q, want %q", test.axis, got, test.want)
+		}
+	}
+}
+
+func TestUnsetAxis(t *testing.T) {
+	backend := NewRecordingBackend()
+	plot, _ := NewPlotWithOptions(3, WithBackend(backend), WithTerminal("dumb"),
+		WithTransport(TransportDatablock))
+	plot.SetXrange(-2, 2)
+	plot.SetZrange(0, 1)
+	plot.SetOffsets(0, 0, 0.5, 0.25)
+	plot.AddPointGroup("A", "points", [][]int{{1}, {2}, {3}})
+	backend.Reset()
+	if err := plot.SetAxis(NewAxis("x", AxisUnset())); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"reset",
+		"set zrange [0:1]",
+		"set offsets 0, 0, 0.5, 0.25",
+		"splot $G1 title \"A\"  with points",
+	}
+	if got := backend.Commands(); !slices.Equal(got, want) {
+		t.Errorf("Wrong commands:\n got %q\nwant %q", got, want)
+	}
+	if err := plot.SetAxis(NewAxis("y2", AxisMin(0))); err == nil {
+		t.Error("Expected the y2 axis to be rejected for a 3D plot")
+	}
+	if err := plot.SetAxis(NewAxis("w")); err == nil {
+		t.Error("Expected an error for an unknown axis")
+	}
+}
@@ -0,0 +1,138 @@
+package glot
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// Backend is the interface through which a Plot talks to gnuplot.
+// The default Backend is a gnuplot subprocess, see NewPlot. Other
+// implementations can be passed to NewPlotWithBackend, e.g. a
+// RecordingBackend for tests.
+//
+// The methods of a Backend return ctx.Err() once ctx is done, a Backend
+// running gnuplot kills it in that case.
+type Backend interface {
+	// Command sends a single line of gnuplot input, without the
+	// trailing newline. It doesn't wait for the command to be processed.
+	Command(ctx context.Context, cmd string) error
+	// Data sends raw input, e.g. the contents of a datablock.
+	Data(ctx context.Context, p []byte) error
+	// Sync waits until all input sent so far has been processed and
+	// returns the output produced in the meantime. A *CommandError is
+	// returned if one of the commands was rejected.
+	Sync(ctx context.Context) (string, error)
+	// Close ends the session and releases all resources.
+	Close(ctx context.Context) error
+}
+
+// RecordingBackend is an in-memory Backend that records everything a
+// Plot sends, so the generated commands can be inspected without a
+// gnuplot installation.
+//
+// Usage
+//
+//	backend := glot.NewRecordingBackend()
+//	plot, _ := glot.NewPlotWithOptions(2, glot.WithBackend(backend), glot.WithTerminal("dumb"))
+//	plot.SetTitle("Test Results")
+//	backend.Commands() // ["set term dumb", "set title \"Test Results\""]
+type RecordingBackend struct {
+	// Reply is called on every Sync with the input sent since the
+	// previous Sync and its results are returned by Sync. If nil, Sync
+	// returns no output and no error.
+	Reply func(input []string) (string, error)
+
+	mu       sync.Mutex
+	commands []string
+	pending  []string
+	closed   bool
+}
+
+// NewRecordingBackend makes an empty RecordingBackend.
+func NewRecordingBackend() *RecordingBackend {
+	return &RecordingBackend{}
+}
+
+// Command records a command.
+func (b *RecordingBackend) Command(ctx context.Context, cmd string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return ErrNotRunning
+	}
+	b.commands = append(b.commands, cmd)
+	b.pending = append(b.pending, cmd)
+	return nil
+}
+
+// Data records raw input. Every line of data is recorded as if it was
+// a command.
+func (b *RecordingBackend) Data(ctx context.Context, p []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return ErrNotRunning
+	}
+	lines := strings.Split(strings.TrimSuffix(string(p), "\n"), "\n")
+	b.commands = append(b.commands, lines...)
+	b.pending = append(b.pending, lines...)
+	return nil
+}
+
+// Sync hands the input sent since the previous Sync to Reply.
+func (b *RecordingBackend) Sync(ctx context.Context) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	b.mu.Lock()
+	pending := b.pending
+	b.pending = nil
+	reply := b.Reply
+	b.mu.Unlock()
+	if reply == nil {
+		return "", nil
+	}
+	return reply(pending)
+}
+
+// Close marks the backend as closed.
+func (b *RecordingBackend) Close(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	return nil
+}
+
+// Commands returns a copy of all input recorded so far.
+func (b *RecordingBackend) Commands() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]string(nil), b.commands...)
+}
+
+// Alive reports whether the backend wasn't closed yet.
+func (b *RecordingBackend) Alive() bool {
+	return !b.Closed()
+}
+
+// Closed reports whether Close was called.
+func (b *RecordingBackend) Closed() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.closed
+}
+
+// Reset forgets all recorded input.
+func (b *RecordingBackend) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.commands = nil
+	b.pending = nil
+}
@@ -0,0 +1,50 @@
+package glot
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestRecordingBackend(t *testing.T) {
+	backend := NewRecordingBackend()
+	plot, err := NewPlotWithOptions(2, WithBackend(backend), WithTerminal("wxt enhanced"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	plot.SetTitle("Test plot")
+	plot.SetXrange(-2, 2)
+	want := []string{
+		"set term wxt enhanced",
+		"set title \"Test plot\"",
+		"set xrange [-2:2]",
+	}
+	if got := backend.Commands(); !slices.Equal(got, want) {
+		t.Errorf("Wrong commands:\n got %q\nwant %q", got, want)
+	}
+	plot.Close()
+	if !backend.Closed() {
+		t.Error("Expected the backend to be closed with the plot")
+	}
+}
+
+func TestRecordingBackendReply(t *testing.T) {
+	backend := NewRecordingBackend()
+	plot, _ := NewPlotWithBackend(2, backend, false)
+	backend.Reply = func(input []string) (string, error) {
+		return "", &CommandError{Line: input[0], Message: "unrecognized option"}
+	}
+	err := plot.Cmd("set foo")
+	cmdErr, ok := err.(*CommandError)
+	if !ok || cmdErr.Line != "set foo" {
+		t.Errorf("Expected a CommandError for 'set foo', got %v", err)
+	}
+	backend.Reply = func(input []string) (string, error) {
+		return "", &CommandError{Line: input[0], Message: "empty y range", Warning: true}
+	}
+	if err := plot.Cmd("plot 1"); err != nil {
+		t.Errorf("Cmd shouldn't fail on warnings, got %v", err)
+	}
+	if err := plot.CheckedCmd("plot 1"); err == nil {
+		t.Error("CheckedCmd should fail on warnings")
+	}
+}
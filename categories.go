@@ -0,0 +1,199 @@
+package glot
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Categories is PointGroup data with a label for each value, passed to
+// AddPointGroup, e.g. for bar charts by service name or region. The
+// values are plotted at the positions 0, 1, 2, ... of the axis, which
+// shows the labels as its tic labels. Categories are read from text
+// data, so they're never passed in binary.
+//
+// Usage
+//
+//	plot.AddPointGroup("requests", "boxes", glot.Categories{
+//		Labels: []string{"api", "auth", "billing"},
+//		Values: []float64{120, 45, 80},
+//	})
+type Categories struct {
+	Labels []string    `json:"labels"`
+	Values []float64   `json:"values"`
+	Extra  [][]float64 `json:"extra,omitempty"` // further columns, e.g. the widths of boxes
+	Axis   string      `json:"axis,omitempty"`  // axis showing the labels, "x" (default) or "y"
+}
+
+// MarshalJSON writes the categories with NaN and infinite values as
+// strings, like the data of a PointGroupSpec.
+func (c Categories) MarshalJSON() ([]byte, error) {
+	type plain Categories
+	return json.Marshal(struct {
+		plain
+		Values []specFloat   `json:"values"`
+		Extra  [][]specFloat `json:"extra,omitempty"`
+	}{plain(c), convertFloats[specFloat](c.Values), convertColumns[specFloat](c.Extra)})
+}
+
+// UnmarshalJSON reads categories written by MarshalJSON.
+func (c *Categories) UnmarshalJSON(data []byte) error {
+	type plain Categories
+	var v struct {
+		plain
+		Values []specFloat   `json:"values"`
+		Extra  [][]specFloat `json:"extra,omitempty"`
+	}
+	if err := decodeStrict(data, &v); err != nil {
+		return err
+	}
+	*c = Categories(v.plain)
+	c.Values = convertFloats[float64](v.Values)
+	c.Extra = convertColumns[float64](v.Extra)
+	return nil
+}
+
+// axis returns the axis showing the labels.
+func (c Categories) axis() string {
+	if c.Axis == "" {
+		return "x"
+	}
+	return c.Axis
+}
+
+// categoryColumns converts categorical data to columns of numbers, the
+// positions of the categories and their values. The labels are written
+// next to the columns, see writeGroupColumns.
+func (plot *Plot) categoryColumns(c Categories) ([][]float64, error) {
+	if plot.dimensions != 2 {
+		return nil, &gnuplotError{"categories can only be plotted in 2D plots"}
+	}
+	if c.axis() != "x" && c.axis() != "y" {
+		return nil, &gnuplotError{fmt.Sprintf("invalid category axis '%s', expected x or y", c.Axis)}
+	}
+	if len(c.Values) != len(c.Labels) {
+		return nil, &gnuplotError{fmt.Sprintf("%d labels but %d values", len(c.Labels), len(c.Values))}
+	}
+	for _, column := range c.Extra {
+		if len(column) != len(c.Labels) {
+			return nil, &gnuplotError{fmt.Sprintf("%d labels but %d values in an extra column", len(c.Labels), len(column))}
+		}
+	}
+	positions := make([]float64, len(c.Labels))
+	for i := range positions {
+		positions[i] = float64(i)
+	}
+	if c.axis() == "y" {
+		return append([][]float64{c.Values, positions}, c.Extra...), nil
+	}
+	return append([][]float64{positions, c.Values}, c.Extra...), nil
+}
+
+// checkStyle checks whether the categories can be plotted with style.
+func (c Categories) checkStyle(style string) error {
+	if style == "histograms" && c.axis() != "x" {
+		return &gnuplotError{"histograms can only show categories on the x-axis"}
+	}
+	return nil
+}
+
+// plottedColumns returns the number of numeric columns plotted with
+// style. Histograms place the bars themselves and skip the positions.
+func (c Categories) plottedColumns(style string) int {
+	if style == "histograms" {
+		return 1 + len(c.Extra)
+	}
+	return 2 + len(c.Extra)
+}
+
+// using returns the using spec that plots the categories with style,
+// taking the tic labels from the last column with xtic() or ytic().
+func (c Categories) using(style string) string {
+	columns := 2 + len(c.Extra)
+	var spec []string
+	for i := columns - c.plottedColumns(style) + 1; i <= columns; i++ {
+		spec = append(spec, strconv.Itoa(i))
+	}
+	return fmt.Sprintf("%s:%stic(%d)", strings.Join(spec, ":"), c.axis(), columns+1)
+}
+
+// quoteLabel quotes a label for a data file. gnuplot has no escapes in
+// quoted data, so double quotes become single quotes and line breaks
+// spaces.
+func quoteLabel(label string) string {
+	return "\"" + strings.NewReplacer("\"", "'", "\r", " ", "\n", " ").Replace(label) + "\""
+}
+
+type ticsConfig struct {
+	rotate *float64
+	font   string
+}
+
+// TicsOption configures the tic labels set with SetXTics and SetYTics.
+type TicsOption func(*ticsConfig)
+
+// TicsRotate rotates the tic labels counterclockwise by the given
+// angle in degrees, e.g. 45 for long category names.
+func TicsRotate(degrees float64) TicsOption {
+	return func(c *ticsConfig) {
+		c.rotate = &degrees
+	}
+}
+
+// TicsFont sets the font of the tic labels, e.g. "Helvetica,10".
+func TicsFont(font string) TicsOption {
+	return func(c *ticsConfig) {
+		c.font = font
+	}
+}
+
+// SetXTics puts the given labels as tics on the x-axis, replacing the
+// automatic tics. Without labels the automatic tics are restored, the
+// options still apply to them.
+//
+// Usage
+//
+//	plot.SetXTics(map[float64]string{0: "api", 1: "auth", 2: "billing"}, glot.TicsRotate(45))
+func (plot *Plot) SetXTics(labels map[float64]string, options ...TicsOption) error {
+	return plot.setTics("x", labels, options)
+}
+
+// SetYTics puts the given labels as tics on the y-axis, see SetXTics.
+//
+// Usage
+//
+//	plot.SetYTics(map[float64]string{0: "low", 50: "medium", 100: "high"}, glot.TicsFont("Helvetica,10"))
+func (plot *Plot) SetYTics(labels map[float64]string, options ...TicsOption) error {
+	return plot.setTics("y", labels, options)
+}
+
+func (plot *Plot) setTics(axis string, labels map[float64]string, options []TicsOption) error {
+	cfg := &ticsConfig{}
+	for _, option := range options {
+		option(cfg)
+	}
+	var spec []string
+	if i := slices.IndexFunc(plot.settings, func(s setting) bool { return s.name == axis+"tics" }); i >= 0 {
+		if words := strings.Fields(plot.settings[i].value); slices.Contains(words, "nomirror") {
+			// keep the secondary axis free, see SetX2Tics
+			spec = append(spec, "nomirror")
+		}
+	}
+	if len(labels) > 0 {
+		tics := make([]string, 0, len(labels))
+		for _, pos := range slices.Sorted(maps.Keys(labels)) {
+			tics = append(tics, fmt.Sprintf("%q %s", labels[pos], formatValue(pos)))
+		}
+		spec = append(spec, "("+strings.Join(tics, ", ")+")")
+	}
+	if cfg.rotate != nil {
+		spec = append(spec, fmt.Sprintf("rotate by %s right", formatValue(*cfg.rotate)))
+	}
+	if cfg.font != "" {
+		spec = append(spec, fmt.Sprintf("font %q", cfg.font))
+	}
+	return plot.set(axis+"tics", strings.Join(spec, " "))
+}
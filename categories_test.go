@@ -0,0 +1,94 @@
+package glot
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestCategories(t *testing.T) {
+	backend := NewRecordingBackend()
+	plot, _ := NewPlotWithOptions(2, WithBackend(backend), WithTerminal("dumb"),
+		WithTransport(TransportDatablock))
+	backend.Reset()
+	requests := Categories{Labels: []string{"api", "auth \"v2\""}, Values: []float64{120, 45}}
+	if err := plot.AddPointGroup("requests", "boxes", requests); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"$G1 << EOD",
+		"0 120 \"api\"",
+		"1 45 \"auth 'v2'\"",
+		"EOD",
+		"reset",
+		"plot $G1 using 1:2:xtic(3) title \"requests\"  with boxes",
+	}
+	if got := backend.Commands(); !slices.Equal(got, want) {
+		t.Errorf("Wrong commands:\n got %q\nwant %q", got, want)
+	}
+
+	backend.Reset()
+	if err := plot.ResetPointGroupStyle("requests", "histograms"); err != nil {
+		t.Fatal(err)
+	}
+	want = []string{
+		"reset",
+		"plot $G1 using 2:xtic(3) title \"requests\"  with histograms",
+	}
+	if got := backend.Commands(); !slices.Equal(got, want) {
+		t.Errorf("Wrong commands:\n got %q\nwant %q", got, want)
+	}
+
+	latency := Categories{Labels: []string{"eu", "us"}, Values: []float64{12, 30},
+		Extra: [][]float64{{1, 2}}, Axis: "y"}
+	if err := plot.AddPointGroup("latency", "xerrorbars", latency); err != nil {
+		t.Fatal(err)
+	}
+	if got := backend.Commands(); !strings.HasSuffix(got[len(got)-1], ", $G2 using 1:2:3:ytic(4) title \"latency\"  with xerrorbars") {
+		t.Errorf("Expected the labels on the y-axis, got %q", got[len(got)-1])
+	}
+	if err := plot.ResetPointGroupStyle("latency", "histograms"); err == nil {
+		t.Error("Expected histograms to be rejected for categories on the y-axis")
+	}
+
+	if err := plot.AddPointGroup("short", "boxes", Categories{Labels: []string{"a", "b"}, Values: []float64{1}}); err == nil {
+		t.Error("Expected an error for fewer values than labels")
+	}
+	plot3d, _ := NewPlotWithBackend(3, NewRecordingBackend(), false)
+	if err := plot3d.AddPointGroup("requests", "points", requests); err == nil {
+		t.Error("Expected categories to be rejected for 3D plots")
+	}
+}
+
+func TestCategoriesBinary(t *testing.T) {
+	backend := NewRecordingBackend()
+	plot, _ := NewPlotWithOptions(2, WithBackend(backend), WithTransport(TransportBinary))
+	defer plot.Close()
+	if err := plot.AddPointGroup("requests", "boxes", Categories{Labels: []string{"api"}, Values: []float64{1}}); err != nil {
+		t.Fatal(err)
+	}
+	if got := plot.PointGroup["requests"].source; strings.Contains(got, "binary") {
+		t.Errorf("Expected categories to be passed as text, got source %s", got)
+	}
+}
+
+func TestSetXTics(t *testing.T) {
+	backend := NewRecordingBackend()
+	plot, _ := NewPlotWithOptions(2, WithBackend(backend))
+	plot.SetX2Tics("")
+	backend.Reset()
+	labels := map[float64]string{1: "auth", 0: "api", 2.5: "billing"}
+	if err := plot.SetXTics(labels, TicsRotate(45), TicsFont("Helvetica,10")); err != nil {
+		t.Fatal(err)
+	}
+	if err := plot.SetYTics(nil, TicsRotate(-30)); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"set xtics nomirror (\"api\" 0, \"auth\" 1, \"billing\" 2.5) rotate by 45 right font \"Helvetica,10\"",
+		"set ytics rotate by -30 right",
+	}
+	if got := backend.Commands(); !slices.Equal(got, want) {
+		t.Errorf("Wrong commands:\n got %q\nwant %q", got, want)
+	}
+}
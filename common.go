@@ -1,8 +1,11 @@
 package glot
 
 import (
+	"context"
 	"fmt"
 	"slices"
+	"strconv"
+	"strings"
 )
 
 // SetTitle sets the title for the plot
@@ -16,8 +19,7 @@ import (
 //	plot.AddPointGroup("Sample 1", "lines", []float64{2, 3, 4, 1})
 //	plot.SetTitle("Test Results")
 func (plot *Plot) SetTitle(title string) error {
-	return plot.Cmd("set title \"%s\" ", title)
-	// return plot.Cmd(fmt.Sprintf("set title \"%s\" ", title))
+	return plot.set("title", fmt.Sprintf("\"%s\"", title))
 }
 
 // SetXLabel changes the label for the x-axis
@@ -32,7 +34,7 @@ func (plot *Plot) SetTitle(title string) error {
 //	 plot.SetTitle("Test Results")
 //		plot.SetXLabel("X-Axis")
 func (plot *Plot) SetXLabel(label string) error {
-	return plot.Cmd("set xlabel '%s'", label)
+	return plot.set("xlabel", fmt.Sprintf("'%s'", label))
 }
 
 // SetYLabel changes the label for the y-axis
@@ -47,7 +49,7 @@ func (plot *Plot) SetXLabel(label string) error {
 //	 plot.SetTitle("Test Results")
 //		plot.SetYLabel("Y-Axis")
 func (plot *Plot) SetYLabel(label string) error {
-	return plot.Cmd("set ylabel '%s'", label)
+	return plot.set("ylabel", fmt.Sprintf("'%s'", label))
 }
 
 // SetZLabel changes the label for the z-axis
@@ -62,7 +64,7 @@ func (plot *Plot) SetYLabel(label string) error {
 //	 plot.SetTitle("Test Results")
 //		plot.SetZLabel("Z-Axis")
 func (plot *Plot) SetZLabel(label string) error {
-	return plot.Cmd("set zlabel '%s'", label)
+	return plot.set("zlabel", fmt.Sprintf("'%s'", label))
 }
 
 // SetLabels Functions helps to set labels for x, y, z axis  simultaneously
@@ -114,7 +116,7 @@ func (plot *Plot) SetLabels(labels ...string) error {
 //
 //	SetMXtics(5)
 func (plot *Plot) SetMXtics(n int) error {
-	return plot.Cmd("set mxtics %d", n)
+	return plot.set("mxtics", strconv.Itoa(n))
 }
 
 // SetMYtics sets the minor tick marks of the y-axis
@@ -123,7 +125,7 @@ func (plot *Plot) SetMXtics(n int) error {
 //
 //	SetMYtics(5)
 func (plot *Plot) SetMYtics(n int) error {
-	return plot.Cmd("set mytics %d", n)
+	return plot.set("mytics", strconv.Itoa(n))
 }
 
 // SetGrid sets a grid to a plot
@@ -136,7 +138,7 @@ func (plot *Plot) SetGrid(format string) error {
 	if len(format) == 0 {
 		format = fmt.Sprintf("lt %d lc rgb \"grey\"", 1)
 	}
-	return plot.Cmd("set grid %s", format)
+	return plot.set("grid", format)
 }
 
 // SetXrange changes the range for the x-axis
@@ -151,7 +153,7 @@ func (plot *Plot) SetGrid(format string) error {
 //	plot.SetTitle("Test Results")
 //	plot.SetXrange(-2,2)
 func (plot *Plot) SetXrange(start int, end int) error {
-	return plot.Cmd("set xrange [%d:%d]", start, end)
+	return plot.SetAxis(NewAxis("x", AxisBounds(float64(start), float64(end))))
 }
 
 // SetLogscale changes the scale of an axis to log
@@ -166,7 +168,12 @@ func (plot *Plot) SetXrange(start int, end int) error {
 //	plot.AddPointGroup("rates", "circle", [][]float64{{2, 4, 8, 16, 32}, {4, 7, 4, 10, 3}})
 //	plot.SetLogscale("x", 2)
 func (plot *Plot) SetLogscale(axis string, base int) error {
-	return plot.Cmd("set logscale %s %d", axis, base)
+	if strings.Contains(axis, "2") {
+		if err := plot.checkSecondaryAxes(); err != nil {
+			return err
+		}
+	}
+	return plot.set("logscale "+axis, strconv.Itoa(base))
 }
 
 // SetYrange changes the range for the y-axis
@@ -181,7 +188,7 @@ func (plot *Plot) SetLogscale(axis string, base int) error {
 //	 plot.SetTitle("Test Results")
 //		plot.SetYrange(-2,2)
 func (plot *Plot) SetYrange(start int, end int) error {
-	return plot.Cmd("set yrange [%d:%d]", start, end)
+	return plot.SetAxis(NewAxis("y", AxisBounds(float64(start), float64(end))))
 }
 
 // SetZrange changes the range for the z-axis
@@ -196,12 +203,105 @@ func (plot *Plot) SetYrange(start int, end int) error {
 //	 plot.SetTitle("Test Results")
 //		plot.SetZrange(-2,2)
 func (plot *Plot) SetZrange(start int, end int) error {
-	return plot.Cmd("set zrange [%d:%d]", start, end)
+	return plot.SetAxis(NewAxis("z", AxisBounds(float64(start), float64(end))))
+}
+
+// checkSecondaryAxes checks that the plot has x2 and y2 axes.
+func (plot *Plot) checkSecondaryAxes() error {
+	if plot.dimensions == 3 {
+		return &gnuplotError{"secondary axes are only available in 2D plots"}
+	}
+	return nil
+}
+
+// SetX2Label changes the label for the secondary x-axis at the top of
+// the plot. It's shown once the axis has tics, see SetX2Tics.
+//
+// Usage
+//
+//	plot.SetX2Label("Frequency")
+//	plot.SetX2Tics("")
+func (plot *Plot) SetX2Label(label string) error {
+	if err := plot.checkSecondaryAxes(); err != nil {
+		return err
+	}
+	return plot.set("x2label", fmt.Sprintf("'%s'", label))
+}
+
+// SetY2Label changes the label for the secondary y-axis at the right of
+// the plot. It's shown once the axis has tics, see SetY2Tics.
+//
+// Usage
+//
+//	plot.AddPointGroup("throughput", "lines", rps, *glot.NewPlotObjectStyle(glot.SetAxes("x1y2")))
+//	plot.SetY2Label("requests/s")
+//	plot.SetY2Tics("")
+func (plot *Plot) SetY2Label(label string) error {
+	if err := plot.checkSecondaryAxes(); err != nil {
+		return err
+	}
+	return plot.set("y2label", fmt.Sprintf("'%s'", label))
+}
+
+// SetX2range changes the range for the secondary x-axis
+//
+// Usage
+//
+//	plot.SetX2range(0, 100)
+func (plot *Plot) SetX2range(start int, end int) error {
+	return plot.SetAxis(NewAxis("x2", AxisBounds(float64(start), float64(end))))
+}
+
+// SetY2range changes the range for the secondary y-axis
+//
+// Usage
+//
+//	plot.SetY2range(0, 5000)
+func (plot *Plot) SetY2range(start int, end int) error {
+	return plot.SetAxis(NewAxis("y2", AxisBounds(float64(start), float64(end))))
+}
+
+// SetX2Tics draws tics on the secondary x-axis. spec is passed on to
+// `set x2tics`, e.g. "" for automatic tics or "0,10" for a tic every 10
+// units. The tics of the x-axis are no longer mirrored at the top.
+//
+// Usage
+//
+//	plot.SetX2Tics("")
+func (plot *Plot) SetX2Tics(spec string) error {
+	return plot.setSecondaryTics("x", spec)
+}
+
+// SetY2Tics draws tics on the secondary y-axis. spec is passed on to
+// `set y2tics`, e.g. "" for automatic tics or "0,500" for a tic every
+// 500 units. The tics of the y-axis are no longer mirrored at the right.
+//
+// Usage
+//
+//	plot.SetY2Tics("")
+func (plot *Plot) SetY2Tics(spec string) error {
+	return plot.setSecondaryTics("y", spec)
+}
+
+func (plot *Plot) setSecondaryTics(axis, spec string) error {
+	if err := plot.checkSecondaryAxes(); err != nil {
+		return err
+	}
+	prev := slices.Clone(plot.settings)
+	if !slices.ContainsFunc(plot.settings, func(s setting) bool { return s.name == axis+"tics" }) {
+		// the primary tics would be drawn on the secondary axis too
+		plot.putSetting(setting{name: axis + "tics", value: "nomirror"})
+	}
+	if err := plot.set(axis+"2tics", spec); err != nil {
+		plot.settings = prev
+		return err
+	}
+	return nil
 }
 
 // SetSizeRatio changes the axis ratio of the plots
 func (plot *Plot) SetSizeRatio(val int) error {
-	return plot.Cmd("set size ratio %d", val)
+	return plot.set("size", fmt.Sprintf("ratio %d", val))
 }
 
 // SavePlot function is used to save the plot at this point.
@@ -219,13 +319,32 @@ func (plot *Plot) SetSizeRatio(val int) error {
 //		plot.SetZrange(-2,2)
 //	 plot.SavePlot("1.jpeg")
 func (plot *Plot) SavePlot(filename string) (err error) {
+	return plot.SavePlotContext(context.Background(), filename)
+}
+
+// SavePlotContext is like SavePlot but gives up once ctx is done.
+// gnuplot is killed in that case and ctx.Err() is returned.
+func (plot *Plot) SavePlotContext(ctx context.Context, filename string) (err error) {
+	if plot.figure != nil {
+		return plot.figure.SavePlotContext(ctx, filename)
+	}
 	if plot.nplots == 0 {
 		return &gnuplotError{"This plot has 0 curves and therefore its a redundant plot and it can't be printed."}
 	}
-	plot.CheckedCmd("set terminal %s", plot.format)
-	plot.CheckedCmd("set output '%s'", filename)
-	plot.CheckedCmd("replot  ")
-	return nil
+	if err = plot.cmd(ctx, "set terminal %s", plot.format); err != nil {
+		return err
+	}
+	if err = plot.cmd(ctx, "set output '%s'", filename); err != nil {
+		return err
+	}
+	if err = plot.cmd(ctx, "replot  "); err != nil {
+		return err
+	}
+	// gnuplot only flushes the file once the output is closed
+	if err = plot.cmd(ctx, "unset output"); err != nil {
+		return err
+	}
+	return plot.cmd(ctx, "set terminal %s", plot.terminal)
 }
 
 // SetFormat function is used to save the plot at this point.
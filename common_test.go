@@ -1,12 +1,14 @@
 package glot
 
-import "testing"
+import (
+	"slices"
+	"testing"
+)
 
 func TestSetLabels(t *testing.T) {
 	dimensions := 3
-	persist := false
 	debug := false
-	plot, _ := NewPlot(dimensions, persist, debug)
+	plot, _ := NewPlotWithBackend(dimensions, NewRecordingBackend(), debug)
 	err := plot.SetLabels()
 	if err == nil {
 		t.Error("SetLabels raises error when empty string is passed")
@@ -15,11 +17,62 @@ func TestSetLabels(t *testing.T) {
 
 func TestSetFormat(t *testing.T) {
 	dimensions := 3
-	persist := false
 	debug := false
-	plot, _ := NewPlot(dimensions, persist, debug)
+	plot, _ := NewPlotWithBackend(dimensions, NewRecordingBackend(), debug)
 	err := plot.SetFormat("tls")
 	if err == nil {
 		t.Error("SetLabels raises error when non-supported format is passed as an argument.")
 	}
 }
+
+func TestSecondaryAxes(t *testing.T) {
+	backend := NewRecordingBackend()
+	plot, _ := NewPlotWithOptions(2, WithBackend(backend), WithTerminal("dumb"),
+		WithTransport(TransportDatablock))
+	plot.AddPointGroup("latency", "lines", []int{10, 12})
+	y2 := NewPlotObjectStyle(SetAxes("x1y2"))
+	if err := plot.AddPointGroup("throughput", "lines", []int{500, 800}, *y2); err != nil {
+		t.Fatal(err)
+	}
+	plot.SetY2Label("requests/s")
+	plot.SetY2range(0, 1000)
+	plot.SetY2Tics("")
+	plot.SetLogscale("y2", 10)
+	backend.Reset()
+	plot.Render()
+	want := []string{
+		"reset",
+		"set y2label 'requests/s'",
+		"set y2range [0:1000]",
+		"set ytics nomirror",
+		"set y2tics",
+		"set logscale y2 10",
+		"plot $G1 title \"latency\"  with lines, $G2 title \"throughput\" axes x1y2 with lines",
+	}
+	if got := backend.Commands(); !slices.Equal(got, want) {
+		t.Errorf("Wrong commands:\n got %q\nwant %q", got, want)
+	}
+	bad := NewPlotObjectStyle(SetAxes("x3y1"))
+	if err := plot.AddPointGroup("bad", "lines", []int{1}, *bad); err == nil {
+		t.Error("Expected an error for invalid axes")
+	}
+
+	plot3d, _ := NewPlotWithBackend(3, NewRecordingBackend(), false)
+	for name, err := range map[string]error{
+		"SetY2Label":  plot3d.SetY2Label("y2"),
+		"SetX2Label":  plot3d.SetX2Label("x2"),
+		"SetY2range":  plot3d.SetY2range(0, 1),
+		"SetX2range":  plot3d.SetX2range(0, 1),
+		"SetY2Tics":   plot3d.SetY2Tics(""),
+		"SetX2Tics":   plot3d.SetX2Tics(""),
+		"SetLogscale": plot3d.SetLogscale("y2", 10),
+		"SetAxes":     plot3d.AddPointGroup("g", "points", [][]int{{1}, {2}, {3}}, *y2),
+	} {
+		if err == nil {
+			t.Errorf("Expected %s to be rejected for a 3D plot", name)
+		}
+	}
+	if len(plot3d.PointGroup) != 0 || len(plot3d.settings) != 0 {
+		t.Error("Expected the rejected settings and groups not to be kept")
+	}
+}
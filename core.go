@@ -1,19 +1,28 @@
 package glot
 
 import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
 	"fmt"
 	"io"
 	"math"
 	"os"
 	"os/exec"
+	"regexp"
 	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 )
 
 var gGnuplotCmd string
 var gGnuplotPrefix = "go-gnuplot-"
+var gSyncPrefix = "GLOTTER_SYNC_"
 
 const defaultStyle = "points" // The default style for a curve
-const plotCommand = "replot"  // The default style for a curve
 
 func min(a, b int) int {
 	if a < b {
@@ -33,33 +42,91 @@ func min_len(a [][]float64) int {
 	return min_len
 }
 
-func transpose(a [][]float64) ([][]float64, int) {
-	min_len := min_len(a)
-	rows := make([][]float64, min_len)
+// writeColumns writes the rows of the given columns as text, one row
+// per line with the values separated by spaces. Rows are written up to
+// the length of the shortest column. prec is the number of significant
+// digits, -1 writes the shortest representation that reads back exactly.
+func writeColumns(w *bufio.Writer, columns [][]float64, prec int) error {
+	return writeRows(w, columns, prec, false, nil)
+}
+
+// writeRows is writeColumns with an optional time column: if
+// timeColumn is set, the first column holds seconds since the epoch and
+// is written in fixed-point notation at full precision, as gnuplot's
+// timefmt "%s" expects. If labels isn't nil, the label of each row is
+// appended as a quoted string.
+func writeRows(w *bufio.Writer, columns [][]float64, prec int, timeColumn bool, labels []string) error {
+	rows := min_len(columns)
+	// a float64 takes at most 24 characters with the shortest
+	// representation, flushing early keeps the rows in the buffer
+	rowSize := 25 * len(columns)
+	for i := range rows {
+		var label string
+		if labels != nil {
+			label = quoteLabel(labels[i])
+		}
+		if w.Available() < rowSize+len(label)+1 {
+			if err := w.Flush(); err != nil {
+				return err
+			}
+		}
+		buf := w.AvailableBuffer()
+		for j, column := range columns {
+			if j > 0 {
+				buf = append(buf, ' ')
+			}
+			if j == 0 && timeColumn {
+				buf = strconv.AppendFloat(buf, column[i], 'f', -1, 64)
+			} else {
+				buf = strconv.AppendFloat(buf, column[i], 'g', prec, 64)
+			}
+		}
+		if labels != nil {
+			buf = append(buf, ' ')
+			buf = append(buf, label...)
+		}
+		buf = append(buf, '\n')
+		if _, err := w.Write(buf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeBinaryColumns writes the rows of the given columns as records of
+// little-endian float64 values.
+func writeBinaryColumns(w *bufio.Writer, columns [][]float64) error {
+	rows := min_len(columns)
+	rowSize := 8 * len(columns)
 	for i := range rows {
-		for _, d := range a {
-			rows[i] = append(rows[i], d[i])
+		if w.Available() < rowSize {
+			if err := w.Flush(); err != nil {
+				return err
+			}
+		}
+		buf := w.AvailableBuffer()
+		for _, column := range columns {
+			buf = binary.LittleEndian.AppendUint64(buf, math.Float64bits(column[i]))
+		}
+		if _, err := w.Write(buf); err != nil {
+			return err
 		}
 	}
-	return rows, min_len
+	return nil
 }
 
 // Function to intialize the package and check for GNU plot installation
 // This raises an error if GNU plot is not installed
 func init() {
-	var err error
-
 	gnuplotExecutableName := "gnuplot"
 
 	if runtime.GOOS == "windows" {
 		gnuplotExecutableName = "gnuplot.exe"
 	}
 
-	gGnuplotCmd, err = exec.LookPath(gnuplotExecutableName)
-	if err != nil {
-		fmt.Errorf("** could not find path to 'gnuplot':\n%v\n", err)
-		fmt.Errorf("** set custom path to 'gnuplot' ")
-	}
+	// a missing gnuplot is reported by NewPlot, the path can still be
+	// set with SetCustomPathToGNUPlot
+	gGnuplotCmd, _ = exec.LookPath(gnuplotExecutableName)
 }
 
 type gnuplotError struct {
@@ -70,28 +137,336 @@ func (e *gnuplotError) Error() string {
 	return e.err
 }
 
-// plotterProcess is the type for handling gnu commands.
+// CommandError is returned when gnuplot rejects a command. It carries
+// the message printed by gnuplot and the input line it complained about.
+type CommandError struct {
+	Line    string // the offending input line as echoed by gnuplot
+	Message string // the error message printed by gnuplot
+	Warning bool   // gnuplot only issued a warning
+}
+
+func (e *CommandError) Error() string {
+	kind := "error"
+	if e.Warning {
+		kind = "warning"
+	}
+	if e.Line == "" {
+		return fmt.Sprintf("gnuplot %s: %s", kind, e.Message)
+	}
+	return fmt.Sprintf("gnuplot %s: %s (in %q)", kind, e.Message, e.Line)
+}
+
+// gnuplot reports problems as `line <n>: <message>`, optionally prefixed
+// with the name of the file being loaded, and marks the offending token
+// with a caret below the echoed input line.
+var (
+	reErrorLine = regexp.MustCompile(`^\s*(?:"[^"]*",?\s+)?line \d+:\s*(.*)$`)
+	reWarning   = regexp.MustCompile(`(?i)^\s*warning:\s*(.*)$`)
+	reCaret     = regexp.MustCompile(`^\s*\^\s*$`)
+)
+
+// parseResponse splits the lines gnuplot printed on stderr into regular
+// output and the first error (or, lacking one, the first warning).
+// fallback is used as offending line if gnuplot didn't echo one.
+func parseResponse(lines []string, fallback string) ([]string, *CommandError) {
+	var output []string
+	var cmdErr, cmdWarn *CommandError
+	echoed := ""
+	for i, line := range lines {
+		if reCaret.MatchString(line) {
+			if i > 0 {
+				echoed = strings.TrimSpace(strings.TrimPrefix(lines[i-1], "gnuplot>"))
+				if n := len(output); n > 0 && output[n-1] == lines[i-1] {
+					output = output[:n-1]
+				}
+			}
+			continue
+		}
+		if m := reErrorLine.FindStringSubmatch(line); m != nil {
+			msg := m[1]
+			if w := reWarning.FindStringSubmatch(msg); w != nil {
+				if cmdWarn == nil {
+					cmdWarn = &CommandError{Line: echoed, Message: w[1], Warning: true}
+				}
+			} else if cmdErr == nil {
+				cmdErr = &CommandError{Line: echoed, Message: msg}
+			}
+			echoed = ""
+			continue
+		}
+		if w := reWarning.FindStringSubmatch(line); w != nil {
+			if cmdWarn == nil {
+				cmdWarn = &CommandError{Message: w[1], Warning: true}
+			}
+			continue
+		}
+		if strings.TrimSpace(line) != "" {
+			output = append(output, line)
+		}
+	}
+	if cmdErr == nil {
+		cmdErr = cmdWarn
+	}
+	if cmdErr != nil && cmdErr.Line == "" {
+		cmdErr.Line = fallback
+	}
+	return output, cmdErr
+}
+
+// outputPipe collects the lines written by gnuplot on one of its output
+// streams so they can be consumed without ever blocking the subprocess.
+type outputPipe struct {
+	mu    sync.Mutex
+	lines []string
+	done  bool
+	ready chan struct{}
+}
+
+func newOutputPipe(r io.Reader) *outputPipe {
+	p := &outputPipe{ready: make(chan struct{}, 1)}
+	go func() {
+		if c, ok := r.(io.Closer); ok {
+			defer c.Close()
+		}
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			p.mu.Lock()
+			p.lines = append(p.lines, scanner.Text())
+			p.mu.Unlock()
+			p.notify()
+		}
+		p.mu.Lock()
+		p.done = true
+		p.mu.Unlock()
+		p.notify()
+	}()
+	return p
+}
+
+func (p *outputPipe) notify() {
+	select {
+	case p.ready <- struct{}{}:
+	default:
+	}
+}
+
+// next blocks until a line is available. ok is false once the stream
+// is closed and drained or ctx is done. Once exited is closed, next
+// only waits a moment for the remaining output.
+func (p *outputPipe) next(ctx context.Context, exited <-chan struct{}) (line string, ok bool) {
+	var grace <-chan time.Time
+	for {
+		p.mu.Lock()
+		if len(p.lines) > 0 {
+			line = p.lines[0]
+			p.lines = p.lines[1:]
+			p.mu.Unlock()
+			return line, true
+		}
+		done := p.done
+		p.mu.Unlock()
+		if done {
+			return "", false
+		}
+		select {
+		case <-p.ready:
+		case <-ctx.Done():
+			return "", false
+		case <-exited:
+			exited = nil
+			grace = time.After(100 * time.Millisecond)
+		case <-grace:
+			return "", false
+		}
+	}
+}
+
+// plotterProcess is the type for handling gnu commands. It is the
+// default Backend of a Plot.
 type plotterProcess struct {
-	handle *exec.Cmd
-	stdin  io.WriteCloser
+	handle  *exec.Cmd
+	stdin   io.WriteCloser
+	stderr  *outputPipe
+	pending []string // commands sent since the last sync
+	nsync   int      // number of syncs, used for unique sentinels
+	exited  chan struct{}
+	exitErr error // result of waiting for the process, valid once exited is closed
 }
 
 // newPlotterProc function makes the plotterProcess struct
-func newPlotterProc(persist bool) (*plotterProcess, error) {
+func newPlotterProc(cfg *plotConfig) (*plotterProcess, error) {
+	if cfg.path == "" {
+		return nil, &gnuplotError{"could not find path to 'gnuplot', set a custom path with SetCustomPathToGNUPlot or WithGnuplotPath"}
+	}
 	procArgs := []string{}
-	if persist {
+	if cfg.persist {
 		procArgs = append(procArgs, "-persist")
 	}
-	cmd := exec.Command(gGnuplotCmd, procArgs...)
+	procArgs = append(procArgs, cfg.args...)
+	cmd := exec.Command(cfg.path, procArgs...)
+	if len(cfg.env) > 0 {
+		cmd.Env = append(os.Environ(), cfg.env...)
+	}
+	cmd.Dir = cfg.dir
+	// gnuplot may fork helpers for its terminals, they are killed
+	// together with gnuplot on cancellation
+	setProcessGroup(cmd)
 	stdin, err := cmd.StdinPipe()
 	if err != nil {
 		return nil, err
 	}
-	return &plotterProcess{handle: cmd, stdin: stdin}, cmd.Start()
+	// the output is read through pipes of our own, so waiting for the
+	// process to exit doesn't wait for helpers that inherited them
+	stdoutR, stdoutW, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	stderrR, stderrW, err := os.Pipe()
+	if err != nil {
+		stdoutR.Close()
+		stdoutW.Close()
+		return nil, err
+	}
+	cmd.Stdout = stdoutW
+	cmd.Stderr = stderrW
+	err = cmd.Start()
+	stdoutW.Close()
+	stderrW.Close()
+	if err != nil {
+		stdoutR.Close()
+		stderrR.Close()
+		return nil, err
+	}
+	// stdout only carries plot output of terminals without an output
+	// file, e.g. dumb, which must neither mix with the responses on
+	// stderr nor fill up the pipe and block gnuplot
+	go func() {
+		io.Copy(io.Discard, stdoutR)
+		stdoutR.Close()
+	}()
+	proc := &plotterProcess{handle: cmd, stdin: stdin,
+		stderr: newOutputPipe(stderrR), exited: make(chan struct{})}
+	go func() {
+		proc.exitErr = cmd.Wait()
+		close(proc.exited)
+	}()
+	return proc, nil
+}
+
+// Alive reports whether gnuplot is still running.
+func (proc *plotterProcess) Alive() bool {
+	select {
+	case <-proc.exited:
+		return false
+	default:
+		return true
+	}
+}
+
+// watch kills gnuplot if ctx is done before the returned stop function
+// is called.
+func (proc *plotterProcess) watch(ctx context.Context) (stop func() bool) {
+	return context.AfterFunc(ctx, func() {
+		killProcessGroup(proc.handle)
+	})
+}
+
+// Command writes a single line to gnuplot without waiting for it to be
+// processed.
+func (proc *plotterProcess) Command(ctx context.Context, line string) error {
+	proc.pending = append(proc.pending, line)
+	return proc.write(ctx, []byte(line+"\n"))
+}
+
+// Sync waits until gnuplot has processed every command sent so far by
+// echoing a sentinel with printerr and reading stderr up to it, which
+// works wherever set print redirected the output of print. It returns
+// everything else gnuplot printed on stderr in the meantime and a
+// *CommandError if gnuplot complained about one of the commands. Plot
+// output on stdout is discarded.
+func (proc *plotterProcess) Sync(ctx context.Context) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	stop := proc.watch(ctx)
+	defer stop()
+	fallback := ""
+	if n := len(proc.pending); n > 0 {
+		fallback = proc.pending[n-1]
+	}
+	proc.pending = proc.pending[:0]
+	proc.nsync++
+	sentinel := fmt.Sprintf("%s%d", gSyncPrefix, proc.nsync)
+	if _, err := fmt.Fprintf(proc.stdin, "printerr \"%s\"\n", sentinel); err != nil {
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		return "", err
+	}
+	var lines []string
+	for {
+		line, ok := proc.stderr.next(ctx, proc.exited)
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		if !ok {
+			output, cmdErr := parseResponse(lines, fallback)
+			if cmdErr != nil {
+				return strings.Join(output, "\n"), cmdErr
+			}
+			return strings.Join(output, "\n"), &gnuplotError{"gnuplot exited unexpectedly"}
+		}
+		if line == sentinel {
+			break
+		}
+		lines = append(lines, line)
+	}
+	output, cmdErr := parseResponse(lines, fallback)
+	if cmdErr != nil {
+		return strings.Join(output, "\n"), cmdErr
+	}
+	return strings.Join(output, "\n"), nil
+}
+
+// Data writes raw data, e.g. the lines of a datablock, to gnuplot.
+func (proc *plotterProcess) Data(ctx context.Context, p []byte) error {
+	return proc.write(ctx, p)
 }
 
-// Cmd sends a command to the gnuplot subprocess and returns an error
-// if something bad happened in the gnuplot process.
+func (proc *plotterProcess) write(ctx context.Context, p []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	stop := proc.watch(ctx)
+	defer stop()
+	if _, err := proc.stdin.Write(p); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return err
+	}
+	return nil
+}
+
+// Close ends the gnuplot session and waits for the subprocess to exit.
+// gnuplot is killed if ctx is done first.
+func (proc *plotterProcess) Close(ctx context.Context) error {
+	proc.stdin.Close()
+	select {
+	case <-proc.exited:
+		return proc.exitErr
+	case <-ctx.Done():
+		killProcessGroup(proc.handle)
+		<-proc.exited
+		return ctx.Err()
+	}
+}
+
+// Cmd sends a command to the gnuplot subprocess and waits for gnuplot to
+// process it. A *CommandError holding gnuplot's message and the
+// offending line is returned if gnuplot rejected the command.
+// Warnings issued by gnuplot are ignored, see CheckedCmd.
 // ex:
 //
 //	fname := "foo.dat"
@@ -100,32 +475,70 @@ func newPlotterProc(persist bool) (*plotterProcess, error) {
 //	  panic(err)
 //	}
 func (plot *Plot) Cmd(format string, a ...any) error {
-	cmd := fmt.Sprintf(format, a...) + "\n"
-	n, err := io.WriteString(plot.proc.stdin, cmd)
-	if plot.debug {
-		//buf := new(bytes.Buffer)
-		//io.Copy(buf, plot.proc.handle.Stdout)
-		fmt.Printf("cmd> %v", cmd)
-		fmt.Printf("res> %v\n", n)
+	return plot.CmdContext(context.Background(), format, a...)
+}
+
+// CmdContext is like Cmd but gives up once ctx is done. gnuplot is
+// killed in that case and ctx.Err() is returned.
+func (plot *Plot) CmdContext(ctx context.Context, format string, a ...any) error {
+	err := plot.cmd(ctx, format, a...)
+	if err == nil {
+		plot.record(fmt.Sprintf(format, a...))
+	}
+	return err
+}
+
+// cmd is the internal variant of CmdContext, which doesn't record the
+// command for replay.
+func (plot *Plot) cmd(ctx context.Context, format string, a ...any) error {
+	_, err := plot.checkedCmd(ctx, format, a...)
+	if cmdErr, ok := err.(*CommandError); ok && cmdErr.Warning {
+		return nil
 	}
 	return err
 }
 
-// CheckedCmd is a convenience wrapper around Cmd: it will error if the
-// error returned by Cmd isn't nil.
+// CheckedCmd is a stricter variant of Cmd: besides errors it also
+// returns the warnings issued by gnuplot, e.g. for data files
+// without valid points.
 // ex:
 //
 //	fname := "foo.dat"
-//	p.CheckedCmd("plot %s", fname)
-func (plot *Plot) CheckedCmd(format string, a ...any) {
-	err := plot.Cmd(format, a...)
-	if err != nil {
-		_ = fmt.Errorf("** err: %v\n", err)
+//	if err := p.CheckedCmd("plot %s", fname); err != nil {
+//	  panic(err)
+//	}
+func (plot *Plot) CheckedCmd(format string, a ...any) error {
+	_, err := plot.checkedCmd(context.Background(), format, a...)
+	if cmdErr, ok := err.(*CommandError); err == nil || ok && cmdErr.Warning {
+		plot.record(fmt.Sprintf(format, a...))
 	}
+	return err
 }
 
-// A map between os files and file names
-type tmpfilesDb map[string]*os.File
+// checkedCmd sends a command, waits for gnuplot to process it and
+// returns what gnuplot printed in response.
+func (plot *Plot) checkedCmd(ctx context.Context, format string, a ...any) (string, error) {
+	if err := plot.ensureAlive(ctx); err != nil {
+		return "", err
+	}
+	cmd := fmt.Sprintf(format, a...)
+	if plot.logger != nil {
+		plot.logger.Printf("cmd> %v\n", cmd)
+	}
+	if err := plot.backend.Command(ctx, cmd); err != nil {
+		return "", err
+	}
+	res, err := plot.backend.Sync(ctx)
+	if plot.logger != nil {
+		if res != "" {
+			plot.logger.Printf("res> %v\n", res)
+		}
+		if err != nil {
+			plot.logger.Printf("err> %v\n", err)
+		}
+	}
+	return res, err
+}
 
 // Close makes sure all resources used by the gnuplot subprocess are reclaimed.
 // This method is typically called when the Plotter instance is not needed
@@ -135,31 +548,49 @@ type tmpfilesDb map[string]*os.File
 //	if err != nil { /* handle error */ }
 //	defer p.Close()
 func (plot *Plot) Close() (err error) {
-	if plot.proc != nil && plot.proc.handle != nil {
-		plot.proc.stdin.Close()
-		err = plot.proc.handle.Wait()
+	return plot.CloseContext(context.Background())
+}
+
+// CloseContext is like Close but kills gnuplot if it didn't exit before
+// ctx is done, e.g. because of a persistent window.
+func (plot *Plot) CloseContext(ctx context.Context) (err error) {
+	// the cells of a figure share the figure's gnuplot
+	if plot.backend != nil && plot.figure == nil {
+		err = plot.backend.Close(ctx)
 	}
-	plot.ResetPlot()
-	return err
+	plot.closed.Store(true)
+	// the datablocks vanished with gnuplot, only the files remain
+	plot.cleanplot()
+	plot.PointGroup = make(map[string]*PointGroup)
+	plot.order = nil
+	return errors.Join(err, plot.removeDataDir())
 }
 
 func (plot *Plot) cleanplot() (err error) {
-	plot.tmpfiles = make(tmpfilesDb)
 	plot.nplots = 0
 	return err
 }
 
 // ResetPlot is used to reset the whole plot.
-// This removes all the PointGroup's from the plot and makes it new.
+// This removes all the PointGroup's from the plot and their data files
+// and makes it new.
 // Usage
 //
 //	plot.ResetPlot()
 func (plot *Plot) ResetPlot() (err error) {
+	var errs []error
+	for _, pointGroup := range plot.PointGroup {
+		errs = append(errs, plot.releaseData(context.Background(), pointGroup))
+	}
 	plot.cleanplot()
 	plot.PointGroup = make(map[string]*PointGroup) // Adding a mapping between a curve name and a curve
-	return err
+	plot.order = nil
+	plot.dropTimeSettings()
+	return errors.Join(append(errs, plot.removeTmpfiles())...)
 }
 
+// SetCustomPathToGNUPlot sets the path of the gnuplot executable used by
+// all plots made afterwards. Use WithGnuplotPath to configure a single plot.
 func SetCustomPathToGNUPlot(path string) {
 	gGnuplotCmd = path
 }
@@ -1,6 +1,16 @@
 package glot
 
-import "testing"
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"math"
+	"os/exec"
+	"testing"
+	"time"
+)
 
 func TestMin(t *testing.T) {
 	var v int
@@ -9,3 +19,104 @@ func TestMin(t *testing.T) {
 		t.Error("Expected 1, got ", v)
 	}
 }
+
+func TestParseResponse(t *testing.T) {
+	lines := []string{
+		"",
+		"set foo",
+		"    ^",
+		"line 3: unrecognized option - see 'help set'.",
+		"",
+		"1.5",
+	}
+	output, err := parseResponse(lines, "fallback")
+	if err == nil {
+		t.Fatal("Expected an error to be parsed from gnuplot's output")
+	}
+	if err.Warning || err.Line != "set foo" || err.Message != "unrecognized option - see 'help set'." {
+		t.Errorf("Wrong error: %+v", err)
+	}
+	if len(output) != 1 || output[0] != "1.5" {
+		t.Errorf("Wrong output: %q", output)
+	}
+
+	_, err = parseResponse([]string{"warning: Skipping data file with no valid points"}, "plot 'foo'")
+	if err == nil || !err.Warning || err.Line != "plot 'foo'" {
+		t.Errorf("Expected a warning for the fallback line, got %+v", err)
+	}
+}
+
+func TestSyncContext(t *testing.T) {
+	// cat never answers the sync sentinel on stderr, like a hanging gnuplot
+	path, err := exec.LookPath("cat")
+	if err != nil {
+		t.Skip("cat not available")
+	}
+	proc, err := newPlotterProc(&plotConfig{path: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := proc.Command(ctx, "set title 'hang'"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := proc.Sync(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected %v, got %v", context.DeadlineExceeded, err)
+	}
+	closeCtx, closeCancel := context.WithTimeout(context.Background(), time.Second)
+	defer closeCancel()
+	proc.Close(closeCtx)
+	if proc.handle.ProcessState == nil {
+		t.Error("Expected the process to be gone after cancellation")
+	}
+}
+
+func TestCmdContextCanceled(t *testing.T) {
+	plot, _ := NewPlotWithBackend(2, NewRecordingBackend(), false)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := plot.CmdContext(ctx, "set title 'foo'"); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected %v, got %v", context.Canceled, err)
+	}
+}
+
+func TestWriteColumns(t *testing.T) {
+	columns := [][]float64{{1, 2.5, 1e6, 4}, {0.1, 1.0 / 3, math.NaN()}}
+	tests := []struct {
+		prec int
+		want string
+	}{
+		{-1, "1 0.1\n2.5 0.3333333333333333\n1e+06 NaN\n"},
+		{3, "1 0.1\n2.5 0.333\n1e+06 NaN\n"},
+	}
+	for _, test := range tests {
+		var buf bytes.Buffer
+		w := bufio.NewWriter(&buf)
+		if err := writeColumns(w, columns, test.prec); err != nil {
+			t.Fatal(err)
+		}
+		w.Flush()
+		if got := buf.String(); got != test.want {
+			t.Errorf("writeColumns with precision %d:\n got %q\nwant %q", test.prec, got, test.want)
+		}
+	}
+}
+
+func benchmarkWriteColumns(b *testing.B, columns [][]float64) {
+	w := bufio.NewWriter(io.Discard)
+	b.ReportAllocs()
+	for b.Loop() {
+		if err := writeColumns(w, columns, -1); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkWriteColumns1D(b *testing.B) {
+	benchmarkWriteColumns(b, [][]float64{benchmarkData1D(1000000)})
+}
+
+func BenchmarkWriteColumnsND(b *testing.B) {
+	benchmarkWriteColumns(b, benchmarkDataND(1000000))
+}
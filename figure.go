@@ -0,0 +1,353 @@
+package glot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// Figure draws several plots, its cells, in one image using gnuplot's
+// multiplot mode. The cells are arranged in a grid of rows and columns
+// like with `set multiplot layout <rows>,<cols>`, insets can be placed
+// anywhere on top of them. Every cell is a *Plot with the usual API;
+// changing any cell redraws the whole figure.
+//
+// The cells share the gnuplot process of the figure. Closing a cell
+// only releases its data, Close the figure to stop gnuplot.
+type Figure struct {
+	plot    *Plot // runs gnuplot, doesn't plot anything itself
+	rows    int
+	cols    int
+	cfg     figureConfig
+	cells   []*Plot // row by row, nil for unused cells
+	insets  []*inset
+	ncells  int // number of cells made so far, names their datablocks
+	plotted bool
+}
+
+// inset is a cell placed at an arbitrary position of the figure.
+type inset struct {
+	plot                *Plot
+	x, y, width, height float64
+}
+
+type figureConfig struct {
+	title          string
+	left, right    float64
+	bottom, top    float64
+	xspace, yspace float64
+	sharedX        bool
+	sharedY        bool
+	options        []PlotOption
+}
+
+// FigureOption configures a figure made with NewFigure.
+type FigureOption func(*figureConfig)
+
+// FigureTitle sets a title for the whole figure.
+func FigureTitle(title string) FigureOption {
+	return func(c *figureConfig) {
+		c.title = title
+	}
+}
+
+// FigureMargins sets the area of the figure filled by the grid of cells
+// in screen coordinates, i.e. fractions of the image from 0 to 1. The
+// whole image is used by default, less some space for the title.
+func FigureMargins(left, right, bottom, top float64) FigureOption {
+	return func(c *figureConfig) {
+		c.left, c.right, c.bottom, c.top = left, right, bottom, top
+	}
+}
+
+// FigureSpacing sets the space between the cells of the grid in screen
+// coordinates, i.e. fractions of the image.
+func FigureSpacing(x, y float64) FigureOption {
+	return func(c *figureConfig) {
+		c.xspace, c.yspace = x, y
+	}
+}
+
+// FigureSharedX makes the cells of a column share their x-axis: the
+// x-axis label and tic labels are only drawn in the bottom row.
+func FigureSharedX() FigureOption {
+	return func(c *figureConfig) {
+		c.sharedX = true
+	}
+}
+
+// FigureSharedY makes the cells of a row share their y-axis: the y-axis
+// label and tic labels are only drawn in the leftmost column.
+func FigureSharedY() FigureOption {
+	return func(c *figureConfig) {
+		c.sharedY = true
+	}
+}
+
+// FigurePlotOptions configures the gnuplot process of the figure and
+// the defaults of its cells, e.g. the terminal or the transport.
+// Figures can't be made WithAutoRestart, NewFigure returns an error.
+func FigurePlotOptions(options ...PlotOption) FigureOption {
+	return func(c *figureConfig) {
+		c.options = append(c.options, options...)
+	}
+}
+
+// NewFigure makes a new figure with a grid of rows by cols cells.
+//
+// Usage
+//
+//	fig, _ := glot.NewFigure(2, 1, glot.FigureTitle("Load"), glot.FigureSharedX())
+//	defer fig.Close()
+//	top, _ := fig.Subplot(0, 0, 2)
+//	top.SetYLabel("latency")
+//	top.AddPointGroup("p99", "lines", latency)
+//	bottom, _ := fig.Subplot(1, 0, 2)
+//	bottom.SetYLabel("requests/s")
+//	bottom.AddPointGroup("throughput", "lines", throughput)
+//	fig.SavePlot("load.png")
+func NewFigure(rows, cols int, options ...FigureOption) (*Figure, error) {
+	if rows < 1 || cols < 1 {
+		return nil, &gnuplotError{fmt.Sprintf("invalid layout %d,%d", rows, cols)}
+	}
+	cfg := figureConfig{right: 1, top: 1}
+	for _, option := range options {
+		option(&cfg)
+	}
+	if cfg.left >= cfg.right || cfg.bottom >= cfg.top {
+		return nil, &gnuplotError{fmt.Sprintf("invalid margins %v,%v,%v,%v", cfg.left, cfg.right, cfg.bottom, cfg.top)}
+	}
+	probe := &plotConfig{}
+	for _, option := range cfg.options {
+		option(probe)
+	}
+	if probe.autoRestart {
+		// the cells share the backend and would keep talking to the
+		// old process
+		return nil, &gnuplotError{"figures can't be restarted, WithAutoRestart isn't supported"}
+	}
+	plot, err := NewPlotWithOptions(2, cfg.options...)
+	if err != nil {
+		return nil, err
+	}
+	return &Figure{plot: plot, rows: rows, cols: cols, cfg: cfg, cells: make([]*Plot, rows*cols)}, nil
+}
+
+// Subplot returns the cell in the given row and column, counting from
+// 0 at the top left. It's made with the given dimensions on first use.
+func (fig *Figure) Subplot(row, col, dimensions int) (*Plot, error) {
+	if row < 0 || row >= fig.rows || col < 0 || col >= fig.cols {
+		return nil, &gnuplotError{fmt.Sprintf("cell %d,%d is outside the %d,%d layout", row, col, fig.rows, fig.cols)}
+	}
+	cell := fig.cells[row*fig.cols+col]
+	if cell != nil {
+		if cell.dimensions != dimensions {
+			return nil, &gnuplotError{fmt.Sprintf("cell %d,%d is a %d-d plot", row, col, cell.dimensions)}
+		}
+		return cell, nil
+	}
+	cell, err := fig.newCell(dimensions)
+	if err != nil {
+		return nil, err
+	}
+	fig.cells[row*fig.cols+col] = cell
+	return cell, nil
+}
+
+// Inset adds a cell drawn on top of the grid with its lower left corner
+// at x, y and the given width and height, all in screen coordinates,
+// i.e. fractions of the image from 0 to 1.
+//
+// Usage
+//
+//	zoom, _ := fig.Inset(0.6, 0.6, 0.3, 0.3, 2)
+//	zoom.SetXrange(0, 10)
+//	zoom.AddPointGroup("detail", "lines", data)
+func (fig *Figure) Inset(x, y, width, height float64, dimensions int) (*Plot, error) {
+	if width <= 0 || height <= 0 {
+		return nil, &gnuplotError{fmt.Sprintf("invalid inset size %v,%v", width, height)}
+	}
+	cell, err := fig.newCell(dimensions)
+	if err != nil {
+		return nil, err
+	}
+	fig.insets = append(fig.insets, &inset{plot: cell, x: x, y: y, width: width, height: height})
+	return cell, nil
+}
+
+// newCell makes a plot drawn by the figure.
+func (fig *Figure) newCell(dimensions int) (*Plot, error) {
+	if dimensions > 3 || dimensions < 1 {
+		return nil, &gnuplotError{fmt.Sprintf("invalid number of dims '%v'", dimensions)}
+	}
+	root := fig.plot
+	fig.ncells++
+	cell := &Plot{backend: root.backend, logger: root.logger, plotcmd: "plot",
+		dimensions: dimensions, style: "points", format: root.format, terminal: root.terminal,
+		tempDir: root.tempDir, signalCleanup: root.signalCleanup, transport: root.transport,
+		binaryThreshold: root.binaryThreshold, precision: root.precision,
+		blockPrefix: "F" + strconv.Itoa(fig.ncells) + "_", figure: fig}
+	cell.PointGroup = make(map[string]*PointGroup)
+	cell.tmpfiles = make(tmpfilesDb)
+	if dimensions == 3 {
+		cell.plotcmd = "splot"
+	}
+	return cell, nil
+}
+
+// SetFormat sets the format of the image saved by SavePlot, see
+// Plot.SetFormat.
+func (fig *Figure) SetFormat(format string) error {
+	return fig.plot.SetFormat(format)
+}
+
+// Render draws the figure from scratch: every cell is rendered like
+// with Plot.Render in multiplot mode. Changing a cell renders the figure
+// already, Render is only needed after changing gnuplot's state
+// directly, e.g. with Cmd.
+func (fig *Figure) Render() error {
+	return fig.RenderContext(context.Background())
+}
+
+// RenderContext is like Render but gives up once ctx is done. gnuplot
+// is killed in that case and ctx.Err() is returned.
+func (fig *Figure) RenderContext(ctx context.Context) error {
+	script, err := fig.script(ctx)
+	if err != nil {
+		return err
+	}
+	fig.plotted = false
+	for _, cmd := range script {
+		if err := fig.plot.cmd(ctx, "%s", cmd); err != nil {
+			return err
+		}
+	}
+	fig.plotted = len(script) > 2
+	return nil
+}
+
+// script passes the data of all cells to gnuplot unless that was
+// already done and returns the commands that draw the figure.
+func (fig *Figure) script(ctx context.Context) ([]string, error) {
+	cfg := fig.cfg
+	script := []string{"set multiplot"}
+	if cfg.title != "" {
+		script[0] += fmt.Sprintf(" title \"%s\"", cfg.title)
+		if cfg.top == 1 {
+			// leave room for the title
+			cfg.top = 0.95
+		}
+	}
+	width := (cfg.right - cfg.left - float64(fig.cols-1)*cfg.xspace) / float64(fig.cols)
+	height := (cfg.top - cfg.bottom - float64(fig.rows-1)*cfg.yspace) / float64(fig.rows)
+	for i, cell := range fig.cells {
+		if cell == nil || len(cell.PointGroup) == 0 {
+			continue
+		}
+		row, col := i/fig.cols, i%fig.cols
+		x := cfg.left + float64(col)*(width+cfg.xspace)
+		y := cfg.top - float64(row+1)*height - float64(row)*cfg.yspace
+		var shared []string
+		if cfg.sharedX && row < fig.rows-1 {
+			shared = append(shared, "set format x \"\"", "unset xlabel")
+		}
+		if cfg.sharedY && col > 0 {
+			shared = append(shared, "set format y \"\"", "unset ylabel")
+		}
+		cmds, err := cellScript(ctx, cell, x, y, width, height, shared)
+		if err != nil {
+			return nil, err
+		}
+		script = append(script, cmds...)
+	}
+	for _, inset := range fig.insets {
+		if len(inset.plot.PointGroup) == 0 {
+			continue
+		}
+		cmds, err := cellScript(ctx, inset.plot, inset.x, inset.y, inset.width, inset.height, nil)
+		if err != nil {
+			return nil, err
+		}
+		script = append(script, cmds...)
+	}
+	return append(script, "unset multiplot"), nil
+}
+
+// cellScript returns the commands that draw a cell at the given
+// position, followed by extra settings.
+func cellScript(ctx context.Context, cell *Plot, x, y, width, height float64, extra []string) ([]string, error) {
+	cmds, err := cell.renderScript(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// the position goes after reset, which would undo it
+	position := []string{
+		fmt.Sprintf("set origin %s,%s", formatFloat(x), formatFloat(y)),
+		fmt.Sprintf("set size %s,%s", formatFloat(width), formatFloat(height)),
+	}
+	last := len(cmds) - 1
+	script := make([]string, 0, len(cmds)+len(position)+len(extra))
+	script = append(script, cmds[0])
+	script = append(script, position...)
+	script = append(script, cmds[1:last]...)
+	script = append(script, extra...)
+	script = append(script, cmds[last])
+	cell.nplots = len(cell.PointGroup)
+	return script, nil
+}
+
+// formatFloat formats a coordinate rounded to 6 decimals.
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(math.Round(v*1e6)/1e6, 'g', -1, 64)
+}
+
+// SavePlot saves the figure as a single image, see Plot.SavePlot.
+func (fig *Figure) SavePlot(filename string) error {
+	return fig.SavePlotContext(context.Background(), filename)
+}
+
+// SavePlotContext is like SavePlot but gives up once ctx is done.
+// gnuplot is killed in that case and ctx.Err() is returned.
+func (fig *Figure) SavePlotContext(ctx context.Context, filename string) error {
+	if !fig.plotted {
+		return &gnuplotError{"This figure has 0 curves and therefore its a redundant plot and it can't be printed."}
+	}
+	root := fig.plot
+	if err := root.cmd(ctx, "set terminal %s", root.format); err != nil {
+		return err
+	}
+	if err := root.cmd(ctx, "set output '%s'", filename); err != nil {
+		return err
+	}
+	// replot doesn't redraw all of a multiplot
+	if err := fig.RenderContext(ctx); err != nil {
+		return err
+	}
+	if err := root.cmd(ctx, "unset output"); err != nil {
+		return err
+	}
+	return root.cmd(ctx, "set terminal %s", root.terminal)
+}
+
+// Close releases the data of all cells and stops gnuplot.
+func (fig *Figure) Close() error {
+	return fig.CloseContext(context.Background())
+}
+
+// CloseContext is like Close but kills gnuplot if it didn't exit before
+// ctx is done, e.g. because of a persistent window.
+func (fig *Figure) CloseContext(ctx context.Context) error {
+	var errs []error
+	for _, cell := range fig.cells {
+		if cell != nil {
+			errs = append(errs, cell.CloseContext(ctx))
+		}
+	}
+	for _, inset := range fig.insets {
+		errs = append(errs, inset.plot.CloseContext(ctx))
+	}
+	errs = append(errs, fig.plot.CloseContext(ctx))
+	return errors.Join(errs...)
+}
@@ -0,0 +1,82 @@
+package glot
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestFigure(t *testing.T) {
+	backend := NewRecordingBackend()
+	fig, err := NewFigure(2, 2, FigureTitle("Load"), FigureSharedX(), FigureSpacing(0.1, 0.05),
+		FigurePlotOptions(WithBackend(backend), WithTerminal("dumb"), WithTransport(TransportDatablock)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fig.SavePlot("empty.png"); err == nil {
+		t.Error("Expected an error saving an empty figure")
+	}
+	top, _ := fig.Subplot(0, 0, 2)
+	top.SetYLabel("latency")
+	top.AddPointGroup("p99", "lines", []int{1, 2})
+	bottom, _ := fig.Subplot(1, 1, 2)
+	bottom.SetXLabel("time")
+	bottom.AddPointGroup("rps", "lines", []int{3, 4})
+	zoom, _ := fig.Inset(0.6, 0.6, 0.3, 0.3, 2)
+	zoom.AddPointGroup("detail", "points", []int{5})
+	backend.Reset()
+	if err := fig.SavePlot("load.png"); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"set terminal png",
+		"set output 'load.png'",
+		"set multiplot title \"Load\"",
+		"reset",
+		"set origin 0,0.5",
+		"set size 0.45,0.45",
+		"set ylabel 'latency'",
+		"set format x \"\"",
+		"unset xlabel",
+		"plot $F1_1 title \"p99\"  with lines",
+		"reset",
+		"set origin 0.55,0",
+		"set size 0.45,0.45",
+		"set xlabel 'time'",
+		"plot $F2_1 title \"rps\"  with lines",
+		"reset",
+		"set origin 0.6,0.6",
+		"set size 0.3,0.3",
+		"plot $F3_1 title \"detail\"  with points",
+		"unset multiplot",
+		"unset output",
+		"set terminal dumb",
+	}
+	if got := backend.Commands(); !slices.Equal(got, want) {
+		t.Errorf("Wrong commands:\n got %q\nwant %q", got, want)
+	}
+
+	if _, err := fig.Subplot(0, 0, 3); err == nil {
+		t.Error("Expected an error for a cell with other dimensions")
+	}
+	if _, err := fig.Subplot(2, 0, 2); err == nil {
+		t.Error("Expected an error for a cell outside the layout")
+	}
+	if cell, _ := fig.Subplot(0, 0, 2); cell != top {
+		t.Error("Expected Subplot to return the existing cell")
+	}
+	fig.Close()
+	if !backend.Closed() {
+		t.Error("Expected the backend to be closed with the figure")
+	}
+}
+
+func TestFigureAutoRestart(t *testing.T) {
+	backend := NewRecordingBackend()
+	_, err := NewFigure(1, 1, FigurePlotOptions(WithBackend(backend), WithTerminal("dumb"), WithAutoRestart()))
+	if err == nil {
+		t.Error("Expected an error for a figure made WithAutoRestart")
+	}
+	if len(backend.Commands()) != 0 {
+		t.Errorf("Expected no commands for a rejected figure, got %q", backend.Commands())
+	}
+}
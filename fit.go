@@ -0,0 +1,271 @@
+package glot
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// FitResult holds the results of a fit, see Plot.Fit.
+type FitResult struct {
+	Params    map[string]float64 // final values of the parameters
+	Errors    map[string]float64 // asymptotic standard errors of the parameters
+	WSSR      float64            // weighted sum of squared residuals
+	NDF       int                // number of degrees of freedom
+	StdFit    float64            // rms of the residuals, sqrt(WSSR/NDF)
+	Converged bool               // whether the fit converged
+}
+
+type fitConfig struct {
+	errorColumn int
+	overlay     bool
+	style       string
+	spec        []PlotObjectStyle
+}
+
+// FitOption configures a fit made with Plot.Fit.
+type FitOption func(*fitConfig)
+
+// FitErrorColumn makes a weighted fit, using the given column of the
+// PointGroup's data (counting from 1) as errors of the dependent
+// variable, e.g. column 3 for data of the form {x, y, dy}.
+func FitErrorColumn(column int) FitOption {
+	return func(c *fitConfig) {
+		c.errorColumn = column
+	}
+}
+
+// FitOverlay adds the fitted curve to the plot as a PointGroup named
+// "<name> fit", drawn with the given style ("lines" if empty).
+func FitOverlay(style string, spec ...PlotObjectStyle) FitOption {
+	return func(c *fitConfig) {
+		c.overlay = true
+		c.style = style
+		c.spec = spec
+	}
+}
+
+// Fit fits expr to the data of a PointGroup with gnuplot's fit command.
+// expr is a function of x (and y for 3D plots) using the given
+// parameters, which are set to their initial values before the fit.
+// The parameters must be gnuplot identifiers other than the variables
+// of the function and gnuplot's own, e.g. pi or FIT_WSSR. Data of 1D
+// PointGroups is fitted against the point index.
+//
+// Usage
+//
+//	plot.AddPointGroup("Sample1", "points", [][]float64{{1, 2, 3, 4}, {2.1, 3.9, 6.2, 7.8}})
+//	res, _ := plot.Fit("Sample1", "a*x+b", map[string]float64{"a": 1, "b": 0},
+//		glot.FitOverlay("lines"))
+//	fmt.Println(res.Params["a"], res.Errors["a"])
+func (plot *Plot) Fit(name, expr string, params map[string]float64, options ...FitOption) (*FitResult, error) {
+	return plot.FitContext(context.Background(), name, expr, params, options...)
+}
+
+// FitContext is like Fit but gives up once ctx is done. gnuplot is
+// killed in that case and ctx.Err() is returned.
+func (plot *Plot) FitContext(ctx context.Context, name, expr string, params map[string]float64, options ...FitOption) (*FitResult, error) {
+	pointGroup, exists := plot.PointGroup[name]
+	if !exists {
+		return nil, &gnuplotError{fmt.Sprintf("A curve with name %s does not exist.", name)}
+	}
+	if pointGroup.source == "" {
+		return nil, &gnuplotError{fmt.Sprintf("The curve %s has no data to fit.", name)}
+	}
+	if len(params) == 0 {
+		return nil, &gnuplotError{"A fit needs at least one parameter."}
+	}
+	cfg := &fitConfig{}
+	for _, option := range options {
+		option(cfg)
+	}
+
+	columns := 1
+	if d, ok := pointGroup.castedData.([][]float64); ok {
+		columns = len(d)
+	}
+	vars, using, errorKind := "x", "1:2", "yerror"
+	switch {
+	case columns == 1:
+		using = "0:1"
+	case plot.dimensions == 3:
+		vars, using, errorKind = "x,y", "1:2:3", "zerror"
+	}
+	if cfg.errorColumn != 0 {
+		if cfg.errorColumn <= strings.Count(using, ":")+1 || cfg.errorColumn > columns {
+			return nil, &gnuplotError{fmt.Sprintf("invalid error column %d for the curve %s", cfg.errorColumn, name)}
+		}
+		using = fmt.Sprintf("%s:%d %s", using, cfg.errorColumn, errorKind)
+	}
+
+	names := make([]string, 0, len(params))
+	for param := range params {
+		if err := checkParam(param, params); err != nil {
+			return nil, err
+		}
+		names = append(names, param)
+	}
+	slices.Sort(names)
+
+	plot.nfits++
+	function := fmt.Sprintf("glot_fit%d(%s)", plot.nfits, vars)
+	cmds := []string{fmt.Sprintf("%s = %s", function, expr)}
+	for _, param := range names {
+		cmds = append(cmds, fmt.Sprintf("%s = %s", param, strconv.FormatFloat(params[param], 'g', -1, 64)))
+	}
+	cmds = append(cmds,
+		"set fit quiet errorvariables nologfile",
+		fmt.Sprintf("fit %s %s using %s via %s", function, pointGroup.source, using, strings.Join(names, ",")))
+	values, err := plot.runFit(ctx, cmds, names)
+	if err != nil {
+		return nil, err
+	}
+	res := &FitResult{Params: make(map[string]float64), Errors: make(map[string]float64)}
+	for i, param := range names {
+		res.Params[param] = values[2*i]
+		res.Errors[param] = values[2*i+1]
+	}
+	n := 2 * len(names)
+	res.WSSR, res.NDF, res.StdFit, res.Converged = values[n], int(values[n+1]), values[n+2], values[n+3] != 0
+
+	if cfg.overlay {
+		if err := plot.addFitOverlay(ctx, name+" fit", substituteParams(expr, res.Params), cfg); err != nil {
+			return res, err
+		}
+	}
+	return res, nil
+}
+
+// runFit sends the commands of a fit and queries its results. The fit
+// settings it makes are undone afterwards, a reset keeps them.
+func (plot *Plot) runFit(ctx context.Context, cmds, names []string) (values []float64, err error) {
+	defer func() {
+		if rerr := plot.restoreFitSettings(ctx); err == nil {
+			err = rerr
+		}
+	}()
+	for _, cmd := range cmds {
+		if err := plot.cmd(ctx, "%s", cmd); err != nil {
+			return nil, err
+		}
+	}
+	exprs := make([]string, 0, 2*len(names)+4)
+	for _, param := range names {
+		exprs = append(exprs, param, param+"_err")
+	}
+	exprs = append(exprs, "FIT_WSSR", "FIT_NDF", "FIT_STDFIT", "FIT_CONVERGED")
+	return plot.queryFloats(ctx, exprs...)
+}
+
+// restoreFitSettings sets the fit settings back to gnuplot's defaults
+// and to what was set with Cmd.
+func (plot *Plot) restoreFitSettings(ctx context.Context) error {
+	if err := plot.cmd(ctx, "set fit results noerrorvariables logfile default"); err != nil {
+		return err
+	}
+	for _, s := range plot.settings {
+		if isOption(s.name, "fit", 3) {
+			if err := plot.cmd(ctx, "%s", s); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+var reIdentifier = regexp.MustCompile(`^[A-Za-z_]\w*$`)
+
+// Names that can't be used as fit parameters: the variables of the
+// fitted function, gnuplot's constants and the dummy variable of
+// parametric plots.
+var reservedParams = []string{"x", "y", "t", "pi", "NaN", "I"}
+
+// Prefixes of the variables set by gnuplot and glot.
+var reservedParamPrefixes = []string{"GPVAL_", "FIT_", "MOUSE_", "ARG", "glot_"}
+
+// checkParam checks that param is a gnuplot identifier that neither
+// shadows a variable nor collides with the error variable of another
+// parameter.
+func checkParam(param string, params map[string]float64) error {
+	if !reIdentifier.MatchString(param) {
+		return &gnuplotError{fmt.Sprintf("invalid fit parameter '%s', expected a gnuplot identifier", param)}
+	}
+	if slices.Contains(reservedParams, param) ||
+		slices.ContainsFunc(reservedParamPrefixes, func(p string) bool { return strings.HasPrefix(param, p) }) {
+		return &gnuplotError{fmt.Sprintf("invalid fit parameter '%s', the name is used by gnuplot", param)}
+	}
+	if base, ok := strings.CutSuffix(param, "_err"); ok {
+		if _, ok := params[base]; ok {
+			return &gnuplotError{fmt.Sprintf("invalid fit parameter '%s', it holds the error of '%s'", param, base)}
+		}
+	}
+	return nil
+}
+
+// addFitOverlay adds the fitted expression as a PointGroup.
+func (plot *Plot) addFitOverlay(ctx context.Context, name, function string, cfg *fitConfig) error {
+	style := cfg.style
+	if style == "" {
+		style = "lines"
+	}
+	return plot.addFunction(ctx, name, style, function, cfg.spec)
+}
+
+// addFunction adds a PointGroup plotting a gnuplot expression.
+func (plot *Plot) addFunction(ctx context.Context, name, style, function string, spec []PlotObjectStyle) error {
+	if _, exists := plot.PointGroup[name]; exists {
+		return &gnuplotError{fmt.Sprintf("A PointGroup with the name %s  already exists, please use another name of the curve or remove this curve before using another one with the same name.", name)}
+	}
+	curve := &PointGroup{name: name, dimensions: plot.dimensions, style: style, set: true,
+		function: function, plotObjectStyles: spec}
+	if err := curve.checkStyle(style); err != nil {
+		return err
+	}
+	return plot.addGroup(ctx, curve)
+}
+
+// substituteParams replaces the parameters in a gnuplot expression by
+// their values, so the expression no longer depends on gnuplot
+// variables that may change with later fits.
+func substituteParams(expr string, params map[string]float64) string {
+	isStart := func(c byte) bool { return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' }
+	isPart := func(c byte) bool { return isStart(c) || c >= '0' && c <= '9' }
+	var sb strings.Builder
+	for i := 0; i < len(expr); {
+		c := expr[i]
+		switch {
+		case c == '"' || c == '\'':
+			// skip string literals
+			j := i + 1
+			for j < len(expr) && expr[j] != c {
+				if expr[j] == '\\' && c == '"' {
+					j++
+				}
+				j++
+			}
+			j = min(j+1, len(expr))
+			sb.WriteString(expr[i:j])
+			i = j
+		case isStart(c) && (i == 0 || !isPart(expr[i-1]) && expr[i-1] != '.'):
+			j := i + 1
+			for j < len(expr) && isPart(expr[j]) {
+				j++
+			}
+			ident := expr[i:j]
+			call := strings.HasPrefix(strings.TrimLeft(expr[j:], " \t"), "(")
+			if v, ok := params[ident]; ok && !call {
+				sb.WriteString("(" + strconv.FormatFloat(v, 'g', -1, 64) + ")")
+			} else {
+				sb.WriteString(ident)
+			}
+			i = j
+		default:
+			sb.WriteByte(c)
+			i++
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,112 @@
+package glot
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestFit(t *testing.T) {
+	backend := NewRecordingBackend()
+	plot, _ := NewPlotWithOptions(2, WithBackend(backend), WithTerminal("dumb"),
+		WithTransport(TransportDatablock))
+	plot.AddPointGroup("Sample1", "yerrorbars", [][]float64{{1, 2, 3, 4}, {2.1, 3.9, 6.2, 7.8}, {0.1, 0.2, 0.1, 0.2}})
+	backend.Reset()
+	backend.Reply = func(input []string) (string, error) {
+		if strings.HasPrefix(input[len(input)-1], "printerr a, a_err") {
+			return "1.94 0.05 0.1 0.12 0.25 2 0.35 1", nil
+		}
+		return "", nil
+	}
+	res, err := plot.Fit("Sample1", "a*x+b", map[string]float64{"a": 1, "b": 0},
+		FitErrorColumn(3), FitOverlay("lines"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"glot_fit1(x) = a*x+b",
+		"a = 1",
+		"b = 0",
+		"set fit quiet errorvariables nologfile",
+		"fit glot_fit1(x) $G1 using 1:2:3 yerror via a,b",
+		"printerr a, a_err, b, b_err, FIT_WSSR, FIT_NDF, FIT_STDFIT, FIT_CONVERGED",
+		"set fit results noerrorvariables logfile default",
+		"reset",
+		"plot $G1 title \"Sample1\"  with yerrorbars, (1.94)*x+(0.1) title \"Sample1 fit\"  with lines",
+	}
+	if got := backend.Commands(); !slices.Equal(got, want) {
+		t.Errorf("Wrong commands:\n got %q\nwant %q", got, want)
+	}
+	if res.Params["a"] != 1.94 || res.Errors["a"] != 0.05 || res.Params["b"] != 0.1 || res.Errors["b"] != 0.12 {
+		t.Errorf("Wrong parameters: %v +- %v", res.Params, res.Errors)
+	}
+	if res.WSSR != 0.25 || res.NDF != 2 || res.StdFit != 0.35 || !res.Converged {
+		t.Errorf("Wrong statistics: %+v", res)
+	}
+	if _, exists := plot.PointGroup["Sample1 fit"]; !exists {
+		t.Error("Expected the fitted curve to be added to the plot")
+	}
+
+	if _, err := plot.Fit("Sample1", "a*x", map[string]float64{"a": 1}, FitErrorColumn(2)); err == nil {
+		t.Error("Expected an error for an error column holding the dependent variable")
+	}
+	if _, err := plot.Fit("Missing", "a*x", map[string]float64{"a": 1}); err == nil {
+		t.Error("Expected an error for a missing PointGroup")
+	}
+	for _, params := range []map[string]float64{
+		{"x": 1},
+		{"slope a": 1},
+		{"1a": 1},
+		{"pi": 1},
+		{"FIT_WSSR": 1},
+		{"a": 1, "a_err": 0},
+	} {
+		if _, err := plot.Fit("Sample1", "a*x", params); err == nil {
+			t.Errorf("Expected an error for the parameters %v", params)
+		}
+	}
+}
+
+func TestFitRestoresSettings(t *testing.T) {
+	backend := NewRecordingBackend()
+	plot, _ := NewPlotWithOptions(2, WithBackend(backend), WithTerminal("dumb"),
+		WithTransport(TransportDatablock))
+	plot.AddPointGroup("Sample1", "points", []float64{1, 2, 3})
+	plot.Cmd("set fit logfile 'fits.log'")
+	backend.Reset()
+	backend.Reply = func(input []string) (string, error) {
+		if strings.HasPrefix(input[len(input)-1], "printerr a, a_err") {
+			return "1 0.1 0 1 0 1", nil
+		}
+		return "", nil
+	}
+	if _, err := plot.Fit("Sample1", "a*x", map[string]float64{"a": 1}); err != nil {
+		t.Fatal(err)
+	}
+	got := backend.Commands()
+	want := []string{
+		"set fit results noerrorvariables logfile default",
+		"set fit logfile 'fits.log'",
+	}
+	if !slices.Equal(got[len(got)-2:], want) {
+		t.Errorf("Expected the fit settings to be restored, got %q", got)
+	}
+}
+
+func TestSubstituteParams(t *testing.T) {
+	params := map[string]float64{"a": -2, "b": 1.5e-3, "e": 3}
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"a*x+b", "(-2)*x+(0.0015)"},
+		{"a*exp(-x/b) + 1.5e-3*e", "(-2)*exp(-x/(0.0015)) + 1.5e-3*(3)"},
+		{"a(x) + ab", "a(x) + ab"},
+		{`a*strlen("a b")`, `(-2)*strlen("a b")`},
+	}
+	for _, test := range tests {
+		if got := substituteParams(test.expr, params); got != test.want {
+			t.Errorf("substituteParams(%q) = %q, want %q", test.expr, got, test.want)
+		}
+	}
+}
@@ -40,8 +40,7 @@ func (plot *Plot) AddFunc2d(name string, style string, x []float64, fct Func2d,
 	combined := [][]float64{}
 	combined = append(combined, x)
 	combined = append(combined, y)
-	plot.AddPointGroup(name, style, combined, spec...)
-	return nil
+	return plot.AddPointGroup(name, style, combined, spec...)
 }
 
 // AddFunc3d is used to make a 3-d plot of the format z = Function(x,y)
@@ -85,6 +84,5 @@ func (plot *Plot) AddFunc3d(name string, style string, x []float64, y []float64,
 	combined = append(combined, x)
 	combined = append(combined, y)
 	combined = append(combined, z)
-	plot.AddPointGroup(name, style, combined, spec...)
-	return nil
+	return plot.AddPointGroup(name, style, combined, spec...)
 }
@@ -6,9 +6,8 @@ import (
 
 func TestAddFunc3d(t *testing.T) {
 	dimensions := 3
-	persist := false
 	debug := false
-	plot, _ := NewPlot(dimensions, persist, debug)
+	plot, _ := NewPlotWithBackend(dimensions, NewRecordingBackend(), debug)
 	fct := func(x, y float64) float64 { return x - y }
 	groupName := "Stright Line"
 	style := "lines"
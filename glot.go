@@ -8,9 +8,13 @@
 package glot
 
 import (
+	"bufio"
+	"context"
 	"fmt"
-	"os"
+	"slices"
 	"strings"
+	"sync/atomic"
+	"time"
 )
 
 // Plot is the basic type representing a plot.
@@ -23,16 +27,37 @@ import (
 // The Pointgroups can be dynamically added and removed from a plot
 // And style changes can also be made dynamically.
 type Plot struct {
-	proc       *plotterProcess
-	debug      bool
-	plotcmd    string
-	nplots     int                    // number of currently active plots
-	tmpfiles   tmpfilesDb             // A temporary file used for saving data
-	dimensions int                    // dimensions of the plot
-	PointGroup map[string]*PointGroup // A map between Curve name and curve type. This maps a name to a given curve in a plot. Only one curve with a given name exists in a plot.
-	format     string                 // The saving format of the plot. This could be PDF, PNG, JPEG and so on.
-	style      string                 // style of the plot
-	title      string                 // The title of the plot.
+	backend         Backend
+	logger          Logger // receives the debug output, nil if disabled
+	plotcmd         string
+	nplots          int                     // number of currently active plots
+	tmpfiles        tmpfilesDb              // A temporary file used for saving data
+	tempDir         string                  // directory the plot's own temporary directory is made in
+	dataDir         string                  // the plot's own temporary directory, made on first use
+	signalCleanup   bool                    // remove dataDir on SIGINT and SIGTERM
+	transport       Transport               // how the data of the PointGroups is passed to gnuplot
+	nblocks         int                     // number of datablocks defined so far
+	blockPrefix     string                  // prefix of the names of the datablocks
+	binaryThreshold int                     // number of values above which data files are binary
+	precision       int                     // significant digits of values in text data, -1 for the shortest exact representation
+	nfits           int                     // number of fits made so far
+	settings        []setting               // settings made with the setters and Cmd, applied on every render
+	history         []string                // definitions sent with Cmd, replayed on restart
+	closed          atomic.Bool             // Close was called or the data was removed on a signal, see WithSignalCleanup
+	autoRestart     bool                    // restart gnuplot when it exited
+	restarting      bool                    // a restart is restoring the plot
+	newBackend      func() (Backend, error) // starts a new gnuplot, nil for custom backends
+	figure          *Figure                 // the figure the plot is a cell of, if any
+	timeLocation    *time.Location          // time zone times are shown in, UTC if nil
+	timeOffset      int                     // seconds the times of the passed TimeSeries data are shifted by
+	annotations     map[int]Annotation      // labels, arrows and shapes added with AddAnnotation by ID
+	dimensions      int                     // dimensions of the plot
+	PointGroup      map[string]*PointGroup  // A map between Curve name and curve type. This maps a name to a given curve in a plot. Only one curve with a given name exists in a plot.
+	order           []string                // names of the PointGroups in the order they are plotted
+	format          string                  // The saving format of the plot. This could be PDF, PNG, JPEG and so on.
+	terminal        string                  // The terminal the plot is displayed on.
+	style           string                  // style of the plot
+	title           string                  // The title of the plot.
 }
 
 // NewPlot Function makes a new plot with the specified dimensions.
@@ -50,88 +75,157 @@ type Plot struct {
 //	debug       :=> can be used by developers to check the actual commands sent to gnu plot.
 //	persist     :=> used to make the gnu plot window stay open.
 func NewPlot(dimensions int, persist, debug bool) (*Plot, error) {
-	p := &Plot{proc: nil, debug: debug, plotcmd: "plot",
-		nplots: 0, dimensions: dimensions, style: "points", format: "png"}
-	p.PointGroup = make(map[string]*PointGroup) // Adding a mapping between a curve name and a curve
-	p.tmpfiles = make(tmpfilesDb)
-	proc, err := newPlotterProc(persist)
-	if err != nil {
-		return nil, err
+	var options []PlotOption
+	if persist {
+		options = append(options, WithPersist())
 	}
+	if debug {
+		options = append(options, WithDebug())
+	}
+	return NewPlotWithOptions(dimensions, options...)
+}
+
+// NewPlotWithBackend makes a new plot with the specified dimensions that
+// sends its commands to the given Backend instead of a gnuplot subprocess.
+//
+// Usage
+//
+//	backend := glot.NewRecordingBackend()
+//	plot, _ := glot.NewPlotWithBackend(2, backend, false)
+//	plot.AddPointGroup("Sample 1", "lines", []float64{2, 3, 4, 1})
+//	fmt.Println(backend.Commands())
+func NewPlotWithBackend(dimensions int, backend Backend, debug bool) (*Plot, error) {
+	options := []PlotOption{WithBackend(backend)}
+	if debug {
+		options = append(options, WithDebug())
+	}
+	return NewPlotWithOptions(dimensions, options...)
+}
+
+// NewPlotWithOptions makes a new plot with the specified dimensions and
+// configures it with the given options. Unlike the package wide
+// SetCustomPathToGNUPlot, the options only affect this plot.
+//
+// Usage
+//
+//	plot, _ := glot.NewPlotWithOptions(2,
+//		glot.WithGnuplotPath("/opt/gnuplot/bin/gnuplot"),
+//		glot.WithEnv("GNUPLOT_LIB=/opt/gnuplot/share"),
+//		glot.WithTerminal("pngcairo"),
+//		glot.WithTempDir("/var/tmp/plots"),
+//		glot.WithLogger(log.Default()),
+//	)
+func NewPlotWithOptions(dimensions int, options ...PlotOption) (*Plot, error) {
 	// Only 1,2,3 Dimensional plots are supported
-	if p.dimensions > 3 || p.dimensions < 1 {
-		return nil, &gnuplotError{fmt.Sprintf("invalid number of dims '%v'", p.dimensions)}
+	if dimensions > 3 || dimensions < 1 {
+		return nil, &gnuplotError{fmt.Sprintf("invalid number of dims '%v'", dimensions)}
+	}
+	cfg := &plotConfig{path: gGnuplotCmd, binaryThreshold: defaultBinaryThreshold, precision: -1}
+	for _, option := range options {
+		option(cfg)
+	}
+	backend := cfg.backend
+	var newBackend func() (Backend, error)
+	if backend == nil {
+		newBackend = func() (Backend, error) {
+			return newPlotterProc(cfg)
+		}
+		proc, err := newBackend()
+		if err != nil {
+			return nil, err
+		}
+		backend = proc
 	}
+	p := &Plot{backend: backend, logger: cfg.logger, plotcmd: "plot",
+		nplots: 0, dimensions: dimensions, style: "points", format: "png",
+		tempDir: cfg.tempDir, signalCleanup: cfg.signalCleanup, transport: cfg.transport, binaryThreshold: cfg.binaryThreshold, precision: cfg.precision,
+		autoRestart: cfg.autoRestart, newBackend: newBackend, blockPrefix: "G"}
+	p.PointGroup = make(map[string]*PointGroup) // Adding a mapping between a curve name and a curve
+	p.tmpfiles = make(tmpfilesDb)
 	if p.dimensions == 3 {
 		p.plotcmd = "splot"
 	}
-	p.proc = proc
-	p.Cmd("set term %s %s", "wxt", "enhanced")
+	p.terminal = cfg.terminal
+	if p.terminal == "" {
+		term, err := p.detectTerminal(context.Background())
+		if err != nil {
+			backend.Close(context.Background())
+			return nil, err
+		}
+		p.terminal = term
+	}
+	if err := p.cmd(context.Background(), "set term %s", p.terminal); err != nil {
+		backend.Close(context.Background())
+		return nil, err
+	}
 
 	return p, nil
 }
 
-// plot one-dimensional data as a 2D plot
-func (plot *Plot) plot1D(PointGroup *PointGroup) error {
-	f, err := os.CreateTemp(os.TempDir(), gGnuplotPrefix)
-	if err != nil {
-		return err
-	}
-	fname := f.Name()
-	plot.tmpfiles[fname] = f
-	for _, d := range PointGroup.castedData.([]float64) {
-		fmt.Fprintf(f, "%v\n", d)
-	}
-	f.Close()
-	cmd := plot.plotcmd
-	if plot.nplots > 0 {
-		cmd = plotCommand
-	}
-	if PointGroup.style == "" {
-		PointGroup.style = defaultStyle
+// groupSource passes the data of a PointGroup to gnuplot unless that
+// was already done and returns what to plot for it.
+func (plot *Plot) groupSource(ctx context.Context, PointGroup *PointGroup) (string, error) {
+	if PointGroup.function != "" {
+		return PointGroup.function, nil
 	}
-	if PointGroup.name == "" {
-		plot.nplots++
-		return plot.Cmd("%s \"%s\" %v with %s", cmd, fname, PointGroup.plotObjectStyles, PointGroup.style)
-	} else {
-		plot.nplots++
-		return plot.Cmd("%s \"%s\" title \"%s\" %v with %s",
-			cmd, fname, PointGroup.name, PointGroup.plotObjectStyles, PointGroup.style)
+	if _, ok := PointGroup.castedData.([][]float64); ok {
+		return plot.sourceND(ctx, PointGroup)
 	}
+	return plot.source1D(ctx, PointGroup)
 }
 
-// plot multi-dimensional data as either a 2D plot or 3D plot
-func (plot *Plot) plotND(PointGroup *PointGroup) error {
-	// transpose list of columns to list of rows
-	rows, min_len := transpose(PointGroup.castedData.([][]float64))
-
-	f, err := os.CreateTemp(os.TempDir(), gGnuplotPrefix)
-	if err != nil {
-		return err
-	}
-	fname := f.Name()
-	plot.tmpfiles[fname] = f
+// source1D passes one-dimensional data, plotted as a 2D plot.
+func (plot *Plot) source1D(ctx context.Context, PointGroup *PointGroup) (string, error) {
+	data := PointGroup.castedData.([]float64)
+	return plot.dataSource(ctx, PointGroup, dataWriter{
+		text: func(w *bufio.Writer) error {
+			return plot.writeGroupColumns(w, PointGroup, [][]float64{data})
+		},
+		binary: func(w *bufio.Writer) error {
+			return writeBinaryColumns(w, [][]float64{data})
+		},
+		format: "%float64",
+		using:  "0:1",
+		values: len(data),
+	})
+}
 
-	for i := range min_len {
-		fmt.Fprintf(f, "%s\n", strings.Trim(fmt.Sprint(rows[i]), "[]"))
+// sourceND passes multi-dimensional data, plotted as either a 2D plot
+// or 3D plot.
+func (plot *Plot) sourceND(ctx context.Context, PointGroup *PointGroup) (string, error) {
+	data := PointGroup.castedData.([][]float64)
+	dw := dataWriter{
+		text: func(w *bufio.Writer) error {
+			return plot.writeGroupColumns(w, PointGroup, data)
+		},
+		binary: func(w *bufio.Writer) error {
+			return writeBinaryColumns(w, data)
+		},
+		format: strings.Repeat("%float64", len(data)),
+		values: len(data) * min_len(data),
 	}
-
-	f.Close()
-	cmd := plot.plotcmd
-	if plot.nplots > 0 {
-		cmd = plotCommand
+	switch PointGroup.data.(type) {
+	case TimeSeries, Categories:
+		// times are read with timefmt and labels are strings, neither
+		// can be passed in binary
+		dw.binary = nil
 	}
+	return plot.dataSource(ctx, PointGroup, dw)
+}
 
+// plotElement returns the element of the plot command that plots a
+// PointGroup whose data was passed to gnuplot as source. A PointGroup
+// without a name is left out of the key unless its style sets a title.
+func (PointGroup *PointGroup) plotElement(source string) string {
 	if PointGroup.style == "" {
-		PointGroup.style = "points"
+		PointGroup.style = defaultStyle
+	}
+	if slices.ContainsFunc(PointGroup.plotObjectStyles, func(s PlotObjectStyle) bool { return s.Title != nil }) {
+		return fmt.Sprintf("%s %v with %s", source, PointGroup.plotObjectStyles, PointGroup.style)
 	}
 	if PointGroup.name == "" {
-		plot.nplots++
-		return plot.Cmd("%s \"%s\" %s with %s", cmd, fname, PointGroup.plotObjectStyles, PointGroup.style)
-	} else {
-		plot.nplots++
-		return plot.Cmd("%s \"%s\" title \"%s\" %s with %s",
-			// cmd, fname, PointGroup.name, strings.Trim(fmt.Sprint(PointGroup.plotObjectStyles), "[]"), PointGroup.style)
-			cmd, fname, PointGroup.name, PointGroup.plotObjectStyles, PointGroup.style)
+		return fmt.Sprintf("%s notitle %v with %s", source, PointGroup.plotObjectStyles, PointGroup.style)
 	}
+	return fmt.Sprintf("%s title \"%s\" %v with %s",
+		source, PointGroup.name, PointGroup.plotObjectStyles, PointGroup.style)
 }
@@ -0,0 +1,167 @@
+package glot
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Key configures the key (legend) of a plot, see Plot.SetKey. The
+// entries of the key are the PointGroups, see SetCurveTitle and
+// SetNoTitle to change or drop the entry of a PointGroup.
+type Key struct {
+	Hidden       bool    // leave the key out of the plot
+	Position     string  // placement, e.g. "top left" or "outside right bottom"
+	Box          bool    // draw a box around the key
+	Columns      int     // number of columns the entries are spread over, 0 for one column
+	Font         string  // font of the entries, e.g. "Helvetica,10"
+	ReverseOrder bool    // list the entries from the last PointGroup to the first
+	Spacing      float64 // vertical spacing in multiples of the font height, 0 for the default
+	Title        string  // title shown above the entries
+}
+
+// KeyOptions configures a Key made with NewKey.
+type KeyOptions func(*Key)
+
+// Words that make up the position of a key.
+var keyPositions = []string{"inside", "outside", "lmargin", "rmargin", "tmargin", "bmargin",
+	"left", "right", "center", "top", "bottom", "above", "over", "below", "under"}
+
+// KeyHide leaves the key out of the plot.
+func KeyHide() KeyOptions {
+	return func(k *Key) {
+		k.Hidden = true
+	}
+}
+
+// KeyPosition places the key, e.g. "top left" inside the plot or
+// "outside right bottom" next to it.
+func KeyPosition(position string) KeyOptions {
+	return func(k *Key) {
+		k.Position = position
+	}
+}
+
+// KeyBox draws a box around the key.
+func KeyBox() KeyOptions {
+	return func(k *Key) {
+		k.Box = true
+	}
+}
+
+// KeyColumns spreads the entries of the key over the given number of
+// columns, filling the rows first.
+func KeyColumns(columns int) KeyOptions {
+	return func(k *Key) {
+		k.Columns = columns
+	}
+}
+
+// KeyFont sets the font of the entries of the key.
+func KeyFont(font string) KeyOptions {
+	return func(k *Key) {
+		k.Font = font
+	}
+}
+
+// KeyReverseOrder lists the entries of the key from the last PointGroup
+// to the first.
+func KeyReverseOrder() KeyOptions {
+	return func(k *Key) {
+		k.ReverseOrder = true
+	}
+}
+
+// KeySpacing sets the vertical spacing of the entries in multiples of
+// the font height.
+func KeySpacing(spacing float64) KeyOptions {
+	return func(k *Key) {
+		k.Spacing = spacing
+	}
+}
+
+// KeyTitle shows a title above the entries of the key.
+func KeyTitle(title string) KeyOptions {
+	return func(k *Key) {
+		k.Title = title
+	}
+}
+
+// Contructor for a key configuration with optional parameters
+//
+// Usage
+//
+//	plot.SetKey(glot.NewKey(glot.KeyPosition("outside right"), glot.KeyBox()))
+//	plot.SetKey(glot.NewKey(glot.KeyColumns(2), glot.KeyFont("Helvetica,10")))
+//	plot.SetKey(glot.NewKey(glot.KeyHide()))
+func NewKey(options ...KeyOptions) *Key {
+	k := &Key{}
+	for _, option := range options {
+		option(k)
+	}
+	return k
+}
+
+// check checks the configuration of the key.
+func (k *Key) check() error {
+	for _, word := range strings.Fields(k.Position) {
+		if !slices.Contains(keyPositions, word) {
+			return &gnuplotError{fmt.Sprintf("invalid key position '%s', expected words of %v", k.Position, keyPositions)}
+		}
+	}
+	if k.Columns < 0 {
+		return &gnuplotError{fmt.Sprintf("invalid number of key columns %d", k.Columns)}
+	}
+	if k.Spacing < 0 {
+		return &gnuplotError{fmt.Sprintf("invalid key spacing %s", formatValue(k.Spacing))}
+	}
+	return nil
+}
+
+// spec returns the arguments of `set key`.
+func (k *Key) spec() string {
+	var spec []string
+	if k.Position != "" {
+		spec = append(spec, strings.Join(strings.Fields(k.Position), " "))
+	}
+	if k.Box {
+		spec = append(spec, "box")
+	}
+	if k.Columns > 0 {
+		spec = append(spec, "horizontal maxcols "+strconv.Itoa(k.Columns))
+	}
+	if k.ReverseOrder {
+		spec = append(spec, "invert")
+	}
+	if k.Spacing > 0 {
+		spec = append(spec, "spacing "+formatValue(k.Spacing))
+	}
+	if k.Font != "" {
+		spec = append(spec, fmt.Sprintf("font %q", k.Font))
+	}
+	if k.Title != "" {
+		spec = append(spec, fmt.Sprintf("title %q", k.Title))
+	}
+	if len(spec) == 0 {
+		return "default"
+	}
+	return strings.Join(spec, " ")
+}
+
+// SetKey configures the key of the plot, replacing the previous
+// configuration. A Key without options restores gnuplot's default key.
+//
+// Usage
+//
+//	plot.SetKey(glot.NewKey(glot.KeyPosition("top left"), glot.KeyBox(), glot.KeyReverseOrder()))
+//	plot.SetKey(glot.NewKey(glot.KeyHide()))
+func (plot *Plot) SetKey(key *Key) error {
+	if key.Hidden {
+		return plot.apply(setting{name: "key", unset: true})
+	}
+	if err := key.check(); err != nil {
+		return err
+	}
+	return plot.set("key", key.spec())
+}
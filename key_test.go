@@ -0,0 +1,66 @@
+package glot
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSetKey(t *testing.T) {
+	backend := NewRecordingBackend()
+	plot, _ := NewPlotWithOptions(2, WithBackend(backend))
+	backend.Reset()
+	keys := []*Key{
+		NewKey(KeyPosition("outside  right"), KeyBox(), KeyColumns(2), KeyReverseOrder(),
+			KeySpacing(1.5), KeyFont("Helvetica,10"), KeyTitle("Services")),
+		NewKey(KeyHide()),
+		NewKey(),
+	}
+	for _, key := range keys {
+		if err := plot.SetKey(key); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := []string{
+		"set key outside right box horizontal maxcols 2 invert spacing 1.5 font \"Helvetica,10\" title \"Services\"",
+		"unset key",
+		"set key default",
+	}
+	if got := backend.Commands(); !slices.Equal(got, want) {
+		t.Errorf("Wrong commands:\n got %q\nwant %q", got, want)
+	}
+
+	for _, key := range []*Key{NewKey(KeyPosition("top middle")), NewKey(KeyColumns(-1)), NewKey(KeySpacing(-1))} {
+		if err := plot.SetKey(key); err == nil {
+			t.Errorf("Expected an error for the key %+v", key)
+		}
+	}
+	if len(plot.settings) != 1 || plot.settings[0].String() != "set key default" {
+		t.Errorf("Expected invalid keys to leave the settings alone, got %v", plot.settings)
+	}
+}
+
+func TestCurveTitles(t *testing.T) {
+	backend := NewRecordingBackend()
+	plot, _ := NewPlotWithOptions(2, WithBackend(backend), WithTransport(TransportDatablock))
+	plot.SetKey(NewKey(KeyHide()))
+	plot.AddPointGroup("", "lines", []float64{1, 2})
+	plot.AddPointGroup("p99", "lines", []float64{3, 4}, *NewPlotObjectStyle(SetCurveTitle("p99 latency"), SetLineWidth(2)))
+	backend.Reset()
+	if err := plot.AddPointGroup("threshold", "lines", []float64{5, 5}, *NewPlotObjectStyle(SetNoTitle())); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"$G3 << EOD",
+		"5",
+		"5",
+		"EOD",
+		"reset",
+		"unset key",
+		"plot $G1 notitle  with lines, " +
+			"$G2 lw 2.000000 title \"p99 latency\" with lines, " +
+			"$G3 notitle with lines",
+	}
+	if got := backend.Commands(); !slices.Equal(got, want) {
+		t.Errorf("Wrong commands:\n got %q\nwant %q", got, want)
+	}
+}
@@ -0,0 +1,162 @@
+package glot
+
+import "fmt"
+
+// Logger receives the debug output of a plot, i.e. every command sent
+// to gnuplot and its response. *log.Logger satisfies this interface.
+type Logger interface {
+	Printf(format string, v ...any)
+}
+
+// stdoutLogger prints the debug output to stdout.
+type stdoutLogger struct{}
+
+func (stdoutLogger) Printf(format string, v ...any) {
+	fmt.Printf(format, v...)
+}
+
+// plotConfig holds the configuration of a plot, set through PlotOptions.
+type plotConfig struct {
+	path            string   // path of the gnuplot executable
+	args            []string // extra command line arguments
+	env             []string // extra environment variables
+	dir             string   // working directory of gnuplot
+	persist         bool
+	terminal        string // terminal set at construction
+	tempDir         string // directory for the data files
+	signalCleanup   bool
+	transport       Transport
+	binaryThreshold int
+	precision       int
+	autoRestart     bool
+	logger          Logger
+	backend         Backend
+}
+
+// PlotOption configures a plot made with NewPlotWithOptions.
+type PlotOption func(*plotConfig)
+
+// WithGnuplotPath sets the path of the gnuplot executable for this plot,
+// overriding the one found on the PATH or set with SetCustomPathToGNUPlot.
+func WithGnuplotPath(path string) PlotOption {
+	return func(c *plotConfig) {
+		c.path = path
+	}
+}
+
+// WithArgs adds command line arguments for gnuplot.
+func WithArgs(args ...string) PlotOption {
+	return func(c *plotConfig) {
+		c.args = append(c.args, args...)
+	}
+}
+
+// WithEnv adds environment variables in the form "key=value" to the
+// environment gnuplot inherits, e.g. "GNUPLOT_LIB=/usr/share/gnuplot".
+func WithEnv(env ...string) PlotOption {
+	return func(c *plotConfig) {
+		c.env = append(c.env, env...)
+	}
+}
+
+// WithDir sets the working directory of gnuplot. Relative file names,
+// e.g. passed to SavePlot, are resolved against it.
+func WithDir(dir string) PlotOption {
+	return func(c *plotConfig) {
+		c.dir = dir
+	}
+}
+
+// WithPersist makes the gnuplot window stay open after the plot is closed.
+func WithPersist() PlotOption {
+	return func(c *plotConfig) {
+		c.persist = true
+	}
+}
+
+// WithTerminal sets the terminal the plot starts with, e.g. "pngcairo"
+// or "qt enhanced". By default an interactive terminal is chosen if a
+// display is available and dumb otherwise, see TerminalEnv.
+func WithTerminal(terminal string) PlotOption {
+	return func(c *plotConfig) {
+		c.terminal = terminal
+	}
+}
+
+// WithTempDir sets the directory the data files of the plot are
+// written to instead of os.TempDir(). Every plot makes its own
+// directory inside it, which is removed by Close.
+func WithTempDir(dir string) PlotOption {
+	return func(c *plotConfig) {
+		c.tempDir = dir
+	}
+}
+
+// WithTransport selects how the data of the PointGroups is passed to
+// gnuplot, see Transport.
+func WithTransport(transport Transport) PlotOption {
+	return func(c *plotConfig) {
+		c.transport = transport
+	}
+}
+
+// WithBinaryThreshold sets the number of values of a PointGroup above
+// which TransportFile writes a binary instead of a text file. A
+// threshold of 0 or less disables binary files.
+func WithBinaryThreshold(values int) PlotOption {
+	return func(c *plotConfig) {
+		c.binaryThreshold = values
+	}
+}
+
+// WithPrecision sets the number of significant digits of the values
+// written as text. By default the shortest representation that reads
+// back to the exact same float64 is used.
+func WithPrecision(digits int) PlotOption {
+	return func(c *plotConfig) {
+		c.precision = digits
+	}
+}
+
+// WithSignalCleanup removes the data files of the plot when the program
+// receives SIGINT or SIGTERM. The option takes over the termination of
+// the program: once the files are removed, it exits with the status of
+// a program killed by the signal, while handlers the program registered
+// with signal.Notify may still be running. Programs handling these
+// signals themselves should call Close instead.
+func WithSignalCleanup() PlotOption {
+	return func(c *plotConfig) {
+		c.signalCleanup = true
+	}
+}
+
+// WithAutoRestart makes the plot restart gnuplot if it exited, e.g.
+// because it crashed or its window was killed, and restore the plot
+// before the next command is sent, see Plot.Restart. Figures can't be
+// restarted, NewFigure rejects the option.
+func WithAutoRestart() PlotOption {
+	return func(c *plotConfig) {
+		c.autoRestart = true
+	}
+}
+
+// WithLogger enables the debug output of the plot and sends it to logger.
+func WithLogger(logger Logger) PlotOption {
+	return func(c *plotConfig) {
+		c.logger = logger
+	}
+}
+
+// WithDebug enables the debug output of the plot on stdout.
+func WithDebug() PlotOption {
+	return WithLogger(stdoutLogger{})
+}
+
+// WithBackend makes the plot send its commands to backend instead of
+// starting a gnuplot subprocess. Options configuring the subprocess are
+// ignored in that case.
+func WithBackend(backend Backend) PlotOption {
+	return func(c *plotConfig) {
+		c.backend = backend
+	}
+}
@@ -0,0 +1,49 @@
+package glot
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+type testLogger struct {
+	lines []string
+}
+
+func (l *testLogger) Printf(format string, v ...any) {
+	l.lines = append(l.lines, fmt.Sprintf(format, v...))
+}
+
+func TestNewPlotWithOptions(t *testing.T) {
+	backend := NewRecordingBackend()
+	logger := &testLogger{}
+	dir := t.TempDir()
+	plot, err := NewPlotWithOptions(2,
+		WithBackend(backend),
+		WithTerminal("dumb"),
+		WithTempDir(dir),
+		WithLogger(logger),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := backend.Commands()[0]; got != "set term dumb" {
+		t.Errorf("Expected the initial terminal to be set, got %q", got)
+	}
+	if len(logger.lines) == 0 || !strings.Contains(logger.lines[0], "set term dumb") {
+		t.Errorf("Expected the commands to be logged, got %q", logger.lines)
+	}
+	plot.AddPointGroup("Sample1", "points", []float64{1, 2, 3})
+	files, _ := os.ReadDir(dir)
+	if len(files) != 1 {
+		t.Errorf("Expected the data directory of the plot in the temp dir, found %d files", len(files))
+	}
+}
+
+func TestWithGnuplotPath(t *testing.T) {
+	_, err := NewPlotWithOptions(2, WithGnuplotPath("/nonexistent/gnuplot"))
+	if err == nil {
+		t.Error("Expected an error for a gnuplot executable that doesn't exist")
+	}
+}
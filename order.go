@@ -0,0 +1,124 @@
+package glot
+
+import (
+	"fmt"
+	"iter"
+	"slices"
+)
+
+// groups returns the PointGroups in the order they are plotted. Groups
+// added to the PointGroup map directly are plotted last, sorted by name.
+func (plot *Plot) groups() []*PointGroup {
+	groups := make([]*PointGroup, 0, len(plot.PointGroup))
+	for _, name := range plot.order {
+		if pointGroup, ok := plot.PointGroup[name]; ok {
+			groups = append(groups, pointGroup)
+		}
+	}
+	if len(groups) < len(plot.PointGroup) {
+		var names []string
+		for name := range plot.PointGroup {
+			if !slices.Contains(plot.order, name) {
+				names = append(names, name)
+			}
+		}
+		slices.Sort(names)
+		for _, name := range names {
+			groups = append(groups, plot.PointGroup[name])
+		}
+	}
+	return groups
+}
+
+// forget removes a PointGroup from the plot without releasing its data.
+func (plot *Plot) forget(name string) {
+	delete(plot.PointGroup, name)
+	plot.order = slices.DeleteFunc(plot.order, func(n string) bool { return n == name })
+}
+
+// PointGroupNames returns the names of the PointGroups in the order
+// they are plotted, which is the order of the legend. Later groups are
+// drawn on top of earlier ones.
+func (plot *Plot) PointGroupNames() []string {
+	groups := plot.groups()
+	names := make([]string, len(groups))
+	for i, pointGroup := range groups {
+		names[i] = pointGroup.name
+	}
+	return names
+}
+
+// PointGroups iterates over the names and PointGroups of the plot in the
+// order they are plotted.
+//
+// Usage
+//
+//	for name, pointGroup := range plot.PointGroups() {
+//		fmt.Println(name, pointGroup)
+//	}
+func (plot *Plot) PointGroups() iter.Seq2[string, *PointGroup] {
+	groups := plot.groups()
+	return func(yield func(string, *PointGroup) bool) {
+		for _, pointGroup := range groups {
+			if !yield(pointGroup.name, pointGroup) {
+				return
+			}
+		}
+	}
+}
+
+// ReorderPointGroups changes the order the PointGroups are plotted in.
+// The names must be those of all PointGroups of the plot.
+//
+// Usage
+//
+//	plot.AddPointGroup("Sample1", "points", []int32{51, 8, 4, 11})
+//	plot.AddPointGroup("Sample2", "points", []int32{1, 2, 4, 11})
+//	plot.ReorderPointGroups("Sample2", "Sample1")
+func (plot *Plot) ReorderPointGroups(names ...string) error {
+	current := plot.PointGroupNames()
+	sorted := slices.Sorted(slices.Values(names))
+	if !slices.Equal(sorted, slices.Sorted(slices.Values(current))) {
+		return &gnuplotError{fmt.Sprintf("the order %q doesn't name each of the PointGroups %q exactly once", names, current)}
+	}
+	return plot.reorder(slices.Clone(names))
+}
+
+// BringToFront plots a PointGroup last, so it's drawn on top of all
+// others and listed last in the legend.
+func (plot *Plot) BringToFront(name string) error {
+	return plot.move(name, false)
+}
+
+// SendToBack plots a PointGroup first, so all others are drawn on top
+// of it and it's listed first in the legend.
+func (plot *Plot) SendToBack(name string) error {
+	return plot.move(name, true)
+}
+
+func (plot *Plot) move(name string, first bool) error {
+	if _, exists := plot.PointGroup[name]; !exists {
+		return &gnuplotError{fmt.Sprintf("A curve with name %s does not exist.", name)}
+	}
+	names := slices.DeleteFunc(plot.PointGroupNames(), func(n string) bool { return n == name })
+	if first {
+		names = append([]string{name}, names...)
+	} else {
+		names = append(names, name)
+	}
+	return plot.reorder(names)
+}
+
+// reorder sets the order of the PointGroups and renders the plot.
+func (plot *Plot) reorder(names []string) error {
+	if slices.Equal(names, plot.order) {
+		return nil
+	}
+	prev := plot.order
+	plot.order = names
+	if err := plot.Render(); err != nil {
+		plot.order = prev
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package glot
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestPointGroupOrder(t *testing.T) {
+	backend := NewRecordingBackend()
+	plot, _ := NewPlotWithOptions(2, WithBackend(backend), WithTerminal("dumb"),
+		WithTransport(TransportDatablock))
+	for _, name := range []string{"C", "A", "D", "B"} {
+		plot.AddPointGroup(name, "points", []int{1, 2})
+	}
+	plot.RemovePointGroup("D")
+	names := func() []string {
+		var names []string
+		for name := range plot.PointGroups() {
+			names = append(names, name)
+		}
+		return names
+	}
+	if got, want := names(), []string{"C", "A", "B"}; !slices.Equal(got, want) {
+		t.Errorf("Expected insertion order %q, got %q", want, got)
+	}
+	plot.BringToFront("C")
+	plot.SendToBack("B")
+	if got, want := plot.PointGroupNames(), []string{"B", "A", "C"}; !slices.Equal(got, want) {
+		t.Errorf("Expected order %q, got %q", want, got)
+	}
+	cmds := backend.Commands()
+	if got, want := cmds[len(cmds)-1], "plot $G4 title \"B\"  with points, $G2 title \"A\"  with points, $G1 title \"C\"  with points"; got != want {
+		t.Errorf("Wrong plot command:\n got %q\nwant %q", got, want)
+	}
+	if err := plot.ReorderPointGroups("A", "B"); err == nil {
+		t.Error("Expected an error for an order missing a PointGroup")
+	}
+	if err := plot.ReorderPointGroups("A", "B", "B"); err == nil {
+		t.Error("Expected an error for an order naming a PointGroup twice")
+	}
+	if err := plot.ReorderPointGroups("A", "C", "B"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := plot.PointGroupNames(), []string{"A", "C", "B"}; !slices.Equal(got, want) {
+		t.Errorf("Expected order %q, got %q", want, got)
+	}
+	if err := plot.BringToFront("X"); err == nil {
+		t.Error("Expected an error for a missing PointGroup")
+	}
+}
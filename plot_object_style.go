@@ -33,13 +33,28 @@ type PlotObjectColor struct {
 	Value     string
 }
 
+// PlotObjectAxes selects the axes a PointGroup is plotted against.
+type PlotObjectAxes struct {
+	Name  string
+	Value string
+}
+
+// PlotObjectTitle is the entry of a PointGroup in the key, "title" with
+// a custom text or "notitle" to leave the PointGroup out of the key.
+type PlotObjectTitle struct {
+	Name  string
+	Value string
+}
+
 type PlotObjectStyle struct {
-	PointType *PlotObjectType
-	PointSize *PlotObjectSize
-	LineColor *PlotObjectColor
-	LineType  *PlotObjectType
-	LineWidth *PlotObjectSize
-	DashType  *PlotObjectType
+	PointType *PlotObjectType  `json:",omitempty"`
+	PointSize *PlotObjectSize  `json:",omitempty"`
+	LineColor *PlotObjectColor `json:",omitempty"`
+	LineType  *PlotObjectType  `json:",omitempty"`
+	LineWidth *PlotObjectSize  `json:",omitempty"`
+	DashType  *PlotObjectType  `json:",omitempty"`
+	Axes      *PlotObjectAxes  `json:",omitempty"`
+	Title     *PlotObjectTitle `json:",omitempty"`
 }
 
 type PlotObjectOptions func(*PlotObjectStyle)
@@ -76,6 +91,18 @@ func (s PlotObjectStyle) String() string {
 		prependWhitespace(&object_style)
 		object_style += fmt.Sprintf("%s %d", s.DashType.Name, s.DashType.Value)
 	}
+	if s.Axes != nil {
+		prependWhitespace(&object_style)
+		object_style += fmt.Sprintf("%s %s", s.Axes.Name, s.Axes.Value)
+	}
+	if s.Title != nil {
+		prependWhitespace(&object_style)
+		if s.Title.Name == "notitle" {
+			object_style += s.Title.Name
+		} else {
+			object_style += fmt.Sprintf("%s \"%s\"", s.Title.Name, s.Title.Value)
+		}
+	}
 	return object_style
 }
 
@@ -138,6 +165,35 @@ func SetDashType(dt int) PlotObjectOptions {
 	}
 }
 
+// SetAxes plots the data against the given pair of axes: "x1y1" (the
+// default), "x1y2", "x2y1" or "x2y2". Secondary axes are only
+// available in 2D plots.
+func SetAxes(axes string) PlotObjectOptions {
+	return func(s *PlotObjectStyle) {
+		s.Axes = &PlotObjectAxes{}
+		s.Axes.Name = "axes"
+		s.Axes.Value = axes
+	}
+}
+
+// SetCurveTitle shows the PointGroup in the key with the given title
+// instead of its name.
+func SetCurveTitle(title string) PlotObjectOptions {
+	return func(s *PlotObjectStyle) {
+		s.Title = &PlotObjectTitle{}
+		s.Title.Name = "title"
+		s.Title.Value = title
+	}
+}
+
+// SetNoTitle leaves the PointGroup out of the key.
+func SetNoTitle() PlotObjectOptions {
+	return func(s *PlotObjectStyle) {
+		s.Title = &PlotObjectTitle{}
+		s.Title.Name = "notitle"
+	}
+}
+
 // Contructor for a plot object style with optional parameters
 //
 // Usage
@@ -1,7 +1,10 @@
 package glot
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"slices"
 )
 
 // Requirement for each plot style concerning dimensionality and number of columns.
@@ -48,9 +51,27 @@ type PointGroup struct {
 	data             any              // Data inside the curve in any integer/float format
 	castedData       any              // The data inside the curve typecasted to float64
 	set              bool             // TODO: unused
+	file             string           // data file of the curve, written on first plot
+	block            string           // name of the datablock holding the data of the curve
+	source           string           // data source of the curve in plot and fit commands
+	using            string           // using spec needed to plot the data source, if any
+	function         string           // expression plotted instead of data, e.g. a fitted curve
 	plotObjectStyles PlotObjectStyles // style of the plotted data
 }
 
+// dataSpec returns the data source of the curve including the using
+// spec needed to plot it.
+func (pg *PointGroup) dataSpec() string {
+	using := pg.using
+	if c, ok := pg.data.(Categories); ok {
+		using = c.using(pg.style)
+	}
+	if using == "" {
+		return pg.source
+	}
+	return pg.source + " using " + using
+}
+
 // AddPointGroup function adds a group of points to a plot.
 //
 // Usage
@@ -63,6 +84,12 @@ type PointGroup struct {
 //	plot.AddPointGroup("Sample2", "points", []int32{1, 2, 4, 11})
 //	plot.SavePlot("1.png")
 func (plot *Plot) AddPointGroup(name string, style string, data any, spec ...PlotObjectStyle) (err error) {
+	return plot.AddPointGroupContext(context.Background(), name, style, data, spec...)
+}
+
+// AddPointGroupContext is like AddPointGroup but gives up once ctx is
+// done. gnuplot is killed in that case and ctx.Err() is returned.
+func (plot *Plot) AddPointGroupContext(ctx context.Context, name string, style string, data any, spec ...PlotObjectStyle) (err error) {
 	_, exists := plot.PointGroup[name]
 	if exists {
 		return &gnuplotError{fmt.Sprintf("A PointGroup with the name %s  already exists, please use another name of the curve or remove this curve before using another one with the same name.", name)}
@@ -96,10 +123,6 @@ func (plot *Plot) AddPointGroup(name string, style string, data any, spec ...Plo
 			return &gnuplotError{"The dimensions of this PointGroup are not compatible with the dimensions of the plot.\nIf you want to make a 2-d curve you must specify a 2-d plot."}
 		}
 		curve.castedData = d
-
-		plot.plotND(curve)
-		plot.PointGroup[name] = curve
-
 	case [][]float32:
 		if max_cols < len(d) || len(d) < plot.dimensions {
 			return &gnuplotError{"The dimensions of this PointGroup are not compatible with the dimensions of the plot.\nIf you want to make a 2-d curve you must specify a 2-d plot."}
@@ -113,10 +136,6 @@ func (plot *Plot) AddPointGroup(name string, style string, data any, spec ...Plo
 			}
 		}
 		curve.castedData = typeCasteSlice
-
-		plot.plotND(curve)
-		plot.PointGroup[name] = curve
-
 	case [][]int:
 		if max_cols < len(d) || len(d) < plot.dimensions {
 			return &gnuplotError{"The dimensions of this PointGroup are not compatible with the dimensions of the plot.\nIf you want to make a 2-d curve you must specify a 2-d plot."}
@@ -130,10 +149,6 @@ func (plot *Plot) AddPointGroup(name string, style string, data any, spec ...Plo
 			}
 		}
 		curve.castedData = typeCasteSlice
-
-		plot.plotND(curve)
-		plot.PointGroup[name] = curve
-
 	case [][]int8:
 		if max_cols < len(d) || len(d) < plot.dimensions {
 			return &gnuplotError{"The dimensions of this PointGroup are not compatible with the dimensions of the plot.\nIf you want to make a 2-d curve you must specify a 2-d plot."}
@@ -147,10 +162,6 @@ func (plot *Plot) AddPointGroup(name string, style string, data any, spec ...Plo
 			}
 		}
 		curve.castedData = typeCasteSlice
-
-		plot.plotND(curve)
-		plot.PointGroup[name] = curve
-
 	case [][]int16:
 		if max_cols < len(d) || len(d) < plot.dimensions {
 			return &gnuplotError{"The dimensions of this PointGroup are not compatible with the dimensions of the plot.\nIf you want to make a 2-d curve you must specify a 2-d plot."}
@@ -164,10 +175,6 @@ func (plot *Plot) AddPointGroup(name string, style string, data any, spec ...Plo
 			}
 		}
 		curve.castedData = typeCasteSlice
-
-		plot.plotND(curve)
-		plot.PointGroup[name] = curve
-
 	case [][]int32:
 		if max_cols < len(d) || len(d) < plot.dimensions {
 			return &gnuplotError{"The dimensions of this PointGroup are not compatible with the dimensions of the plot.\nIf you want to make a 2-d curve you must specify a 2-d plot."}
@@ -181,10 +188,6 @@ func (plot *Plot) AddPointGroup(name string, style string, data any, spec ...Plo
 			}
 		}
 		curve.castedData = typeCasteSlice
-
-		plot.plotND(curve)
-		plot.PointGroup[name] = curve
-
 	case [][]int64:
 		if max_cols < len(d) || len(d) < plot.dimensions {
 			return &gnuplotError{"The dimensions of this PointGroup are not compatible with the dimensions of the plot.\nIf you want to make a 2-d curve you must specify a 2-d plot."}
@@ -198,14 +201,8 @@ func (plot *Plot) AddPointGroup(name string, style string, data any, spec ...Plo
 			}
 		}
 		curve.castedData = typeCasteSlice
-
-		plot.plotND(curve)
-		plot.PointGroup[name] = curve
-
 	case []float64:
 		curve.castedData = d
-		plot.plot1D(curve)
-		plot.PointGroup[name] = curve
 	case []float32:
 		originalSlice := d
 		typeCasteSlice := make([]float64, len(originalSlice))
@@ -213,8 +210,6 @@ func (plot *Plot) AddPointGroup(name string, style string, data any, spec ...Plo
 			typeCasteSlice[i] = float64(originalSlice[i])
 		}
 		curve.castedData = typeCasteSlice
-		plot.plot1D(curve)
-		plot.PointGroup[name] = curve
 	case []int:
 		originalSlice := d
 		typeCasteSlice := make([]float64, len(originalSlice))
@@ -222,8 +217,6 @@ func (plot *Plot) AddPointGroup(name string, style string, data any, spec ...Plo
 			typeCasteSlice[i] = float64(originalSlice[i])
 		}
 		curve.castedData = typeCasteSlice
-		plot.plot1D(curve)
-		plot.PointGroup[name] = curve
 	case []int8:
 		originalSlice := d
 		typeCasteSlice := make([]float64, len(originalSlice))
@@ -231,8 +224,6 @@ func (plot *Plot) AddPointGroup(name string, style string, data any, spec ...Plo
 			typeCasteSlice[i] = float64(originalSlice[i])
 		}
 		curve.castedData = typeCasteSlice
-		plot.plot1D(curve)
-		plot.PointGroup[name] = curve
 	case []int16:
 		originalSlice := d
 		typeCasteSlice := make([]float64, len(originalSlice))
@@ -240,8 +231,6 @@ func (plot *Plot) AddPointGroup(name string, style string, data any, spec ...Plo
 			typeCasteSlice[i] = float64(originalSlice[i])
 		}
 		curve.castedData = typeCasteSlice
-		plot.plot1D(curve)
-		plot.PointGroup[name] = curve
 	case []int32:
 		originalSlice := d
 		typeCasteSlice := make([]float64, len(originalSlice))
@@ -249,8 +238,6 @@ func (plot *Plot) AddPointGroup(name string, style string, data any, spec ...Plo
 			typeCasteSlice[i] = float64(originalSlice[i])
 		}
 		curve.castedData = typeCasteSlice
-		plot.plot1D(curve)
-		plot.PointGroup[name] = curve
 	case []int64:
 		originalSlice := d
 		typeCasteSlice := make([]float64, len(originalSlice))
@@ -258,13 +245,52 @@ func (plot *Plot) AddPointGroup(name string, style string, data any, spec ...Plo
 			typeCasteSlice[i] = float64(originalSlice[i])
 		}
 		curve.castedData = typeCasteSlice
-		plot.plot1D(curve)
-		plot.PointGroup[name] = curve
+	case TimeSeries:
+		columns, err := plot.timeColumns(d)
+		if err != nil {
+			return err
+		}
+		if max_cols < len(columns) {
+			return &gnuplotError{"The dimensions of this PointGroup are not compatible with the dimensions of the plot.\nIf you want to make a 2-d curve you must specify a 2-d plot."}
+		}
+		curve.castedData = columns
+		prev := slices.Clone(plot.settings)
+		plot.putTimeSettings()
+		if err := plot.addGroup(ctx, curve); err != nil {
+			plot.settings = prev
+			return err
+		}
+		return nil
+	case Categories:
+		columns, err := plot.categoryColumns(d)
+		if err != nil {
+			return err
+		}
+		curve.castedData = columns
+		if err := curve.checkStyle(curve.style); err != nil {
+			return err
+		}
 	default:
 		return &gnuplotError{"invalid number of dims "}
 
 	}
-	return err
+	return plot.addGroup(ctx, curve)
+}
+
+// addGroup adds a PointGroup to the plot and renders it. The group is
+// dropped again if that fails.
+func (plot *Plot) addGroup(ctx context.Context, curve *PointGroup) error {
+	if err := curve.checkAxes(); err != nil {
+		return err
+	}
+	plot.PointGroup[curve.name] = curve
+	plot.order = append(plot.order, curve.name)
+	if err := plot.RenderContext(ctx); err != nil {
+		plot.releaseData(ctx, curve)
+		plot.forget(curve.name)
+		return err
+	}
+	return nil
 }
 
 // RemovePointGroup helps to remove a particular point group from the plot.
@@ -279,17 +305,22 @@ func (plot *Plot) AddPointGroup(name string, style string, data any, spec ...Plo
 //	plot.AddPointGroup("Sample1", "points", []int32{51, 8, 4, 11})
 //	plot.AddPointGroup("Sample2", "points", []int32{1, 2, 4, 11})
 //	plot.RemovePointGroup("Sample1")
-func (plot *Plot) RemovePointGroup(name string) {
-	delete(plot.PointGroup, name)
-	plot.cleanplot()
-	for _, pointGroup := range plot.PointGroup {
-		plot.plot1D(pointGroup)
+func (plot *Plot) RemovePointGroup(name string) error {
+	pointGroup, exists := plot.PointGroup[name]
+	if !exists {
+		return nil
 	}
+	err := plot.releaseData(context.Background(), pointGroup)
+	plot.forget(name)
+	plot.dropTimeSettings()
+	return errors.Join(err, plot.Render())
 }
 
 // ResetPointGroupStyle helps to reset the style of a particular point group in a plot.
 // Using both AddPointGroup and RemovePointGroup you can add or remove point groups.
 // And dynamically change the plots.
+// The PlotObjectStyles of the group are kept. The style must suit the
+// dimensions of the plot and the number of columns of the group.
 //
 // Usage
 //
@@ -304,8 +335,67 @@ func (plot *Plot) ResetPointGroupStyle(name string, style string) (err error) {
 	if !exists {
 		return &gnuplotError{fmt.Sprintf("A curve with name %s does not exist.", name)}
 	}
-	plot.RemovePointGroup(name)
+	if err := pointGroup.checkStyle(style); err != nil {
+		return err
+	}
+	prev := pointGroup.style
 	pointGroup.style = style
-	plot.plot1D(pointGroup)
-	return err
+	if err := plot.Render(); err != nil {
+		pointGroup.style = prev
+		return err
+	}
+	return nil
+}
+
+// checkAxes checks the axes the curve is plotted against.
+func (pg *PointGroup) checkAxes() error {
+	for _, s := range pg.plotObjectStyles {
+		if s.Axes == nil {
+			continue
+		}
+		if !slices.Contains([]string{"x1y1", "x1y2", "x2y1", "x2y2"}, s.Axes.Value) {
+			return &gnuplotError{fmt.Sprintf("invalid axes '%s'", s.Axes.Value)}
+		}
+		if pg.dimensions == 3 {
+			return &gnuplotError{"secondary axes are only available in 2D plots"}
+		}
+	}
+	return nil
+}
+
+// columnData returns the data of the curve as columns, nil for
+// functions.
+func (pg *PointGroup) columnData() [][]float64 {
+	switch d := pg.castedData.(type) {
+	case [][]float64:
+		return d
+	case []float64:
+		return [][]float64{d}
+	}
+	return nil
+}
+
+// columns returns the number of data columns of the curve, 0 for
+// functions.
+func (pg *PointGroup) columns() int {
+	return len(pg.columnData())
+}
+
+// checkStyle checks whether the curve can be plotted with style.
+func (pg *PointGroup) checkStyle(style string) error {
+	maxCols, ok := plotting_styles[style][pg.dimensions]
+	if !ok {
+		return &gnuplotError{fmt.Sprintf("invalid style '%s' for a %d-d plot", style, pg.dimensions)}
+	}
+	cols := pg.columns()
+	if c, ok := pg.data.(Categories); ok {
+		if err := c.checkStyle(style); err != nil {
+			return err
+		}
+		cols = c.plottedColumns(style)
+	}
+	if cols > maxCols {
+		return &gnuplotError{fmt.Sprintf("style '%s' takes at most %d columns, the PointGroup %s has %d", style, maxCols, pg.name, cols)}
+	}
+	return nil
 }
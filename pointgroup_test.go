@@ -1,12 +1,14 @@
 package glot
 
-import "testing"
+import (
+	"slices"
+	"testing"
+)
 
 func TestResetPointGroupStyle(t *testing.T) {
 	dimensions := 2
-	persist := true
 	debug := true
-	plot, _ := NewPlot(dimensions, persist, debug)
+	plot, _ := NewPlotWithBackend(dimensions, NewRecordingBackend(), debug)
 
 	plot.SetTitle("Test plot")
 	plot.SetXLabel("X")
@@ -22,3 +24,57 @@ func TestResetPointGroupStyle(t *testing.T) {
 		t.Error("The specified pointgroup to be reset does not exist")
 	}
 }
+
+func TestRemoveAndResetPointGroup(t *testing.T) {
+	tests := []struct {
+		name                      string
+		dimensions                int
+		data                      any
+		style, newStyle, badStyle string
+		plotcmd                   string
+	}{
+		{"1D", 2, []int{1, 2, 3}, "points", "lines", "pm3d", "plot"},
+		{"2D", 2, [][]int{{1, 2}, {3, 4}, {5, 6}, {7, 8}}, "yerrorbars", "xerrorbars", "lines", "plot"},
+		{"3D", 3, [][]int{{1, 2}, {3, 4}, {5, 6}}, "points", "lines", "yerrorbars", "splot"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			backend := NewRecordingBackend()
+			plot, _ := NewPlotWithOptions(test.dimensions, WithBackend(backend), WithTerminal("dumb"),
+				WithTransport(TransportDatablock))
+			style := NewPlotObjectStyle(SetPointType(7))
+			if err := plot.AddPointGroup("A", test.style, test.data, *style); err != nil {
+				t.Fatal(err)
+			}
+			if err := plot.AddPointGroup("B", test.style, test.data); err != nil {
+				t.Fatal(err)
+			}
+			backend.Reset()
+			if err := plot.ResetPointGroupStyle("A", test.badStyle); err == nil {
+				t.Errorf("Expected style %s to be rejected", test.badStyle)
+			}
+			if err := plot.RemovePointGroup("B"); err != nil {
+				t.Fatal(err)
+			}
+			if err := plot.ResetPointGroupStyle("A", test.newStyle); err != nil {
+				t.Fatal(err)
+			}
+			want := []string{
+				"undefine $G2",
+				"reset",
+				test.plotcmd + " $G1 title \"A\" pt 7 with " + test.style,
+				"reset",
+				test.plotcmd + " $G1 title \"A\" pt 7 with " + test.newStyle,
+			}
+			if got := backend.Commands(); !slices.Equal(got, want) {
+				t.Errorf("Wrong commands:\n got %q\nwant %q", got, want)
+			}
+			backend.Reset()
+			plot.RemovePointGroup("A")
+			want = []string{"undefine $G1", "reset", "clear"}
+			if got := backend.Commands(); !slices.Equal(got, want) {
+				t.Errorf("Wrong commands:\n got %q\nwant %q", got, want)
+			}
+		})
+	}
+}
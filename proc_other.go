@@ -0,0 +1,15 @@
+//go:build !unix
+
+package glot
+
+import "os/exec"
+
+// setProcessGroup is a no-op, process groups are only used on unix.
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// killProcessGroup kills the gnuplot process.
+func killProcessGroup(cmd *exec.Cmd) {
+	if cmd.Process != nil {
+		cmd.Process.Kill()
+	}
+}
@@ -0,0 +1,96 @@
+package glot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Query evaluates a gnuplot expression and returns the result as
+// printed by gnuplot. It can be used to read back variables like
+// GPVAL_X_MIN or the results of computations. The result is printed
+// with printerr, so it's unaffected by set print.
+//
+// Usage
+//
+//	plot.AddPointGroup("Sample1", "points", []int32{51, 8, 4, 11})
+//	max, _ := plot.Query("GPVAL_Y_MAX")
+//	terms, _ := plot.Query("GPVAL_TERMINALS")
+func (plot *Plot) Query(expr string) (string, error) {
+	return plot.QueryContext(context.Background(), expr)
+}
+
+// QueryContext is like Query but gives up once ctx is done. gnuplot is
+// killed in that case and ctx.Err() is returned.
+func (plot *Plot) QueryContext(ctx context.Context, expr string) (string, error) {
+	res, err := plot.checkedCmd(ctx, "printerr %s", expr)
+	if cmdErr, ok := err.(*CommandError); ok && cmdErr.Warning {
+		err = nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(res), nil
+}
+
+// queryFloats evaluates several numeric expressions in one go.
+func (plot *Plot) queryFloats(ctx context.Context, exprs ...string) ([]float64, error) {
+	res, err := plot.QueryContext(ctx, strings.Join(exprs, ", "))
+	if err != nil {
+		return nil, err
+	}
+	fields := strings.Fields(res)
+	if len(fields) != len(exprs) {
+		return nil, &gnuplotError{fmt.Sprintf("expected %d values for %q, got %q", len(exprs), exprs, res)}
+	}
+	values := make([]float64, len(fields))
+	for i, field := range fields {
+		values[i], err = strconv.ParseFloat(field, 64)
+		if err != nil {
+			return nil, &gnuplotError{fmt.Sprintf("invalid value %q for %s", field, exprs[i])}
+		}
+	}
+	return values, nil
+}
+
+// QueryFloat evaluates a numeric gnuplot expression.
+//
+// Usage
+//
+//	v, _ := plot.QueryFloat("sqrt(2)")
+func (plot *Plot) QueryFloat(expr string) (float64, error) {
+	values, err := plot.queryFloats(context.Background(), expr)
+	if err != nil {
+		return 0, err
+	}
+	return values[0], nil
+}
+
+// AxisRange returns the range of an axis ("x", "y", "z", "x2", "y2" or
+// "cb") as used by gnuplot in the most recent plot, i.e. after
+// autoscaling. It can be used to align the ranges of several plots.
+//
+// Usage
+//
+//	plot.AddPointGroup("Sample1", "points", []int32{51, 8, 4, 11})
+//	low, high, _ := plot.AxisRange("y")
+func (plot *Plot) AxisRange(axis string) (low, high float64, err error) {
+	switch axis {
+	case "x", "y", "z", "x2", "y2", "cb":
+	default:
+		return 0, 0, &gnuplotError{fmt.Sprintf("invalid axis '%s'", axis)}
+	}
+	name := "GPVAL_" + strings.ToUpper(axis)
+	values, err := plot.queryFloats(context.Background(), name+"_MIN", name+"_MAX")
+	if err != nil {
+		return 0, 0, err
+	}
+	return values[0], values[1], nil
+}
+
+// Version returns the version of gnuplot including the patchlevel,
+// e.g. "5.4.2".
+func (plot *Plot) Version() (string, error) {
+	return plot.Query(`sprintf("%.1f.%s", GPVAL_VERSION, GPVAL_PATCHLEVEL)`)
+}
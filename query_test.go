@@ -0,0 +1,132 @@
+package glot
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newQueryPlot returns a plot whose backend answers print commands
+// from the given map.
+func newQueryPlot(t *testing.T, answers map[string]string) *Plot {
+	backend := NewRecordingBackend()
+	plot, err := NewPlotWithOptions(2, WithBackend(backend), WithTerminal("dumb"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	backend.Reply = func(input []string) (string, error) {
+		if answer, ok := answers[input[len(input)-1]]; ok {
+			return answer, nil
+		}
+		return "", nil
+	}
+	return plot
+}
+
+func TestQuery(t *testing.T) {
+	plot := newQueryPlot(t, map[string]string{
+		"printerr GPVAL_X_MIN, GPVAL_X_MAX":                            "-1.5 4",
+		`printerr sprintf("%.1f.%s", GPVAL_VERSION, GPVAL_PATCHLEVEL)`: "5.4.2\n",
+		"printerr sqrt(2)":                  "1.4142135623731",
+		"printerr GPVAL_Y_MIN, GPVAL_Y_MAX": "0",
+	})
+	min, max, err := plot.AxisRange("x")
+	if err != nil || min != -1.5 || max != 4 {
+		t.Errorf("AxisRange(x) = %v, %v, %v", min, max, err)
+	}
+	if _, _, err := plot.AxisRange("y"); err == nil {
+		t.Error("Expected an error for an incomplete answer")
+	}
+	if _, _, err := plot.AxisRange("w"); err == nil {
+		t.Error("Expected an error for an invalid axis")
+	}
+	if v, err := plot.Version(); err != nil || v != "5.4.2" {
+		t.Errorf("Version() = %q, %v", v, err)
+	}
+	if v, err := plot.QueryFloat("sqrt(2)"); err != nil || v != 1.4142135623731 {
+		t.Errorf("QueryFloat(sqrt(2)) = %v, %v", v, err)
+	}
+}
+
+// fakeGnuplot answers the sync sentinels and the x range query on
+// stderr like gnuplot and writes ASCII art for each plot on stdout, late
+// enough to arrive while the next query is processed.
+const fakeGnuplot = `#!/bin/sh
+while IFS= read -r line; do
+	case "$line" in
+	'printerr "'*) s=${line#printerr \"}; echo "${s%\"}" >&2 ;;
+	'printerr GPVAL_X_MIN, GPVAL_X_MAX') sleep 0.3; echo "-1.5 4" >&2 ;;
+	plot*) (sleep 0.1; echo "  4 +----------+"; echo "    |    **    |") & ;;
+	esac
+done
+`
+
+func TestAxisRangeAfterDumbPlot(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	path := filepath.Join(t.TempDir(), "gnuplot")
+	if err := os.WriteFile(path, []byte(fakeGnuplot), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	plot, err := NewPlotWithOptions(2, WithGnuplotPath(path), WithTerminal("dumb"), WithTransport(TransportDatablock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer plot.Close()
+	if err := plot.AddPointGroup("Sample1", "lines", []float64{1, 4, 2}); err != nil {
+		t.Fatal(err)
+	}
+	min, max, err := plot.AxisRange("x")
+	if err != nil || min != -1.5 || max != 4 {
+		t.Errorf("AxisRange(x) = %v, %v, %v", min, max, err)
+	}
+}
+
+// fakePrintGnuplot prints like gnuplot: print writes to the file set
+// with set print, printerr always writes to stderr.
+const fakePrintGnuplot = `#!/bin/sh
+out=/dev/stderr
+while IFS= read -r line; do
+	case "$line" in
+	"set print '"*) out=${line#set print \'}; out=${out%\'} ;;
+	'print "'*) s=${line#print \"}; echo "${s%\"}" >>"$out" ;;
+	'printerr "'*) s=${line#printerr \"}; echo "${s%\"}" >&2 ;;
+	'printerr sqrt(2)') echo 1.4142135623731 >&2 ;;
+	esac
+done
+`
+
+func TestSyncAfterSetPrint(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, "gnuplot")
+	if err := os.WriteFile(path, []byte(fakePrintGnuplot), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	plot, err := NewPlotWithOptions(2, WithGnuplotPath(path), WithTerminal("dumb"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer plot.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	out := filepath.Join(dir, "out.txt")
+	if err := plot.CmdContext(ctx, "set print '%s'", out); err != nil {
+		t.Fatal(err)
+	}
+	if err := plot.CmdContext(ctx, `print "redirected"`); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := plot.QueryContext(ctx, "sqrt(2)"); err != nil || v != "1.4142135623731" {
+		t.Errorf("QueryContext(sqrt(2)) = %q, %v", v, err)
+	}
+	if data, err := os.ReadFile(out); err != nil || string(data) != "redirected\n" {
+		t.Errorf("Expected the print output in %s, got %q, %v", out, data, err)
+	}
+}
@@ -0,0 +1,191 @@
+package glot
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// setting is a gnuplot option of the plot, applied with "set <name>
+// <value>" or "unset <name>" whenever the plot is rendered.
+type setting struct {
+	name  string // option including its tag, e.g. "xrange" or "style line 1"
+	value string // arguments of set
+	unset bool
+}
+
+func (s setting) String() string {
+	switch {
+	case s.unset:
+		return "unset " + s.name
+	case s.value == "":
+		return "set " + s.name
+	}
+	return "set " + s.name + " " + s.value
+}
+
+// Options that can be set several times with different tags, e.g.
+// "set label 1 ..." and "set label 2 ...".
+var taggedOptions = []string{"arrow", "label", "object", "linetype", "style"}
+
+// Options that are set for each axis, e.g. "set format x ..." and "set
+// format y ...".
+var axisOptions = []string{"format", "logscale", "autoscale"}
+
+// parseSetting parses a set or unset command.
+func parseSetting(cmd string) (setting, bool) {
+	words, rest := cutWords(cmd, 2)
+	if len(words) < 2 || words[0] != "set" && words[0] != "unset" {
+		return setting{}, false
+	}
+	name := words[1]
+	if slices.Contains(axisOptions, name) {
+		// set format x ..., set format "%g"
+		if axis, tail := cutWords(rest, 1); len(axis) == 1 && slices.Contains(axisNames, axis[0]) {
+			name, rest = name+" "+axis[0], tail
+		}
+	}
+	if slices.Contains(taggedOptions, name) {
+		if name == "style" {
+			// set style line 1 ..., set style data lines
+			var kind []string
+			if kind, rest = cutWords(rest, 1); len(kind) == 1 {
+				name += " " + kind[0]
+			}
+		}
+		if tag, tail := cutWords(rest, 1); len(tag) == 1 {
+			if _, err := strconv.Atoi(tag[0]); err == nil {
+				name, rest = name+" "+tag[0], tail
+			}
+		}
+	}
+	return setting{name: name, value: rest, unset: words[0] == "unset"}, true
+}
+
+// cutWords splits off the first n whitespace separated words of s and
+// returns them and the remainder of s.
+func cutWords(s string, n int) (words []string, rest string) {
+	rest = strings.TrimSpace(s)
+	for len(words) < n && rest != "" {
+		i := strings.IndexFunc(rest, unicode.IsSpace)
+		if i < 0 {
+			return append(words, rest), ""
+		}
+		words = append(words, rest[:i])
+		rest = strings.TrimSpace(rest[i:])
+	}
+	return words, rest
+}
+
+// putSetting stores a setting, replacing an earlier setting of the same
+// option in place so the order of the settings stays stable.
+func (plot *Plot) putSetting(s setting) {
+	i := slices.IndexFunc(plot.settings, func(o setting) bool { return o.name == s.name })
+	if i < 0 {
+		plot.settings = append(plot.settings, s)
+		return
+	}
+	plot.settings[i] = s
+}
+
+// set changes a setting of the plot and redraws the plot if it isn't
+// empty. The setting is dropped if gnuplot rejects it.
+func (plot *Plot) set(name, value string) error {
+	return plot.apply(setting{name: name, value: value})
+}
+
+// apply stores a setting like set, which also covers unset.
+func (plot *Plot) apply(s setting) error {
+	ctx := context.Background()
+	prev := slices.Clone(plot.settings)
+	plot.putSetting(s)
+	var err error
+	if len(plot.PointGroup) == 0 && plot.figure == nil {
+		err = plot.cmd(ctx, "%s", s)
+	} else {
+		err = plot.RenderContext(ctx)
+	}
+	if err != nil {
+		plot.settings = prev
+	}
+	return err
+}
+
+// Render draws the plot from scratch: it resets gnuplot, applies the
+// settings made with the setters and Cmd and plots all PointGroups with
+// a single plot command. The commands only depend on the state of the
+// plot, not on the order of the calls that led to it. The setters and
+// AddPointGroup render the plot themselves, Render is only needed after
+// changing gnuplot's state directly, e.g. with Cmd.
+//
+// Usage
+//
+//	plot.Cmd("set style line 1 lc rgb 'red'")
+//	plot.Render()
+func (plot *Plot) Render() error {
+	return plot.RenderContext(context.Background())
+}
+
+// RenderContext is like Render but gives up once ctx is done. gnuplot
+// is killed in that case and ctx.Err() is returned.
+func (plot *Plot) RenderContext(ctx context.Context) error {
+	if plot.figure != nil {
+		return plot.figure.RenderContext(ctx)
+	}
+	if !plot.Alive() {
+		// restarting gnuplot renders the plot
+		return plot.ensureAlive(ctx)
+	}
+	script, err := plot.renderScript(ctx)
+	if err != nil {
+		return err
+	}
+	if len(plot.PointGroup) == 0 && plot.nplots > 0 {
+		// nothing is plotted, remove what was drawn before
+		script = append(script, "clear")
+	}
+	plot.nplots = 0
+	for _, cmd := range script {
+		if err := plot.cmd(ctx, "%s", cmd); err != nil {
+			return err
+		}
+	}
+	plot.nplots = len(plot.PointGroup)
+	return nil
+}
+
+// renderScript passes the data of all PointGroups to gnuplot unless
+// that was already done and returns the commands that draw the plot.
+func (plot *Plot) renderScript(ctx context.Context) ([]string, error) {
+	plot.refreshTimeData(ctx)
+	return plot.script(func(pointGroup *PointGroup) (string, error) {
+		return plot.groupSource(ctx, pointGroup)
+	})
+}
+
+// script returns the commands that draw the plot, getting the data
+// source of each PointGroup from source.
+func (plot *Plot) script(source func(*PointGroup) (string, error)) ([]string, error) {
+	script := []string{"reset"}
+	for _, s := range plot.settings {
+		script = append(script, s.String())
+	}
+	if tics, ok := plot.timeTics(); ok {
+		script = append(script, tics)
+	}
+	groups := plot.groups()
+	if len(groups) == 0 {
+		return script, nil
+	}
+	elements := make([]string, len(groups))
+	for i, pointGroup := range groups {
+		src, err := source(pointGroup)
+		if err != nil {
+			return nil, err
+		}
+		elements[i] = pointGroup.plotElement(src)
+	}
+	return append(script, plot.plotcmd+" "+strings.Join(elements, ", ")), nil
+}
@@ -0,0 +1,122 @@
+package glot
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestRenderDeterministic(t *testing.T) {
+	render := func(build func(plot *Plot)) []string {
+		backend := NewRecordingBackend()
+		plot, _ := NewPlotWithOptions(2, WithBackend(backend), WithTerminal("dumb"),
+			WithTransport(TransportDatablock))
+		build(plot)
+		backend.Reset()
+		if err := plot.Render(); err != nil {
+			t.Fatal(err)
+		}
+		return backend.Commands()
+	}
+	a := render(func(plot *Plot) {
+		plot.SetTitle("Test plot")
+		plot.AddPointGroup("A", "points", []int{1, 2})
+		plot.AddPointGroup("B", "lines", []int{3, 4})
+		plot.SetXrange(-2, 2)
+	})
+	b := render(func(plot *Plot) {
+		plot.AddPointGroup("B", "lines", []int{3, 4})
+		plot.SetTitle("Old")
+		plot.AddPointGroup("A", "points", []int{1, 2})
+		plot.SetXrange(-2, 2)
+		plot.SetTitle("Test plot")
+		plot.SendToBack("A")
+	})
+	want := []string{
+		"reset",
+		"set title \"Test plot\"",
+		"set xrange [-2:2]",
+		"plot $G1 title \"A\"  with points, $G2 title \"B\"  with lines",
+	}
+	if !slices.Equal(a, want) {
+		t.Errorf("Wrong commands:\n got %q\nwant %q", a, want)
+	}
+	// datablocks are named in the order they were defined
+	want[3] = "plot $G2 title \"A\"  with points, $G1 title \"B\"  with lines"
+	if !slices.Equal(b, want) {
+		t.Errorf("Wrong commands:\n got %q\nwant %q", b, want)
+	}
+}
+
+func TestRenderCmdSettings(t *testing.T) {
+	backend := NewRecordingBackend()
+	plot, _ := NewPlotWithOptions(2, WithBackend(backend), WithTerminal("dumb"),
+		WithTransport(TransportDatablock))
+	plot.Cmd("set style line 1 lc rgb 'red'")
+	plot.Cmd("set style line 2 lc rgb 'blue'")
+	plot.Cmd("set  style line 1 lc rgb 'green'")
+	plot.Cmd("unset key")
+	plot.Cmd("plot sin(x)")
+	plot.AddPointGroup("A", "points", []int{1, 2})
+	want := []string{
+		"reset",
+		"set style line 1 lc rgb 'green'",
+		"set style line 2 lc rgb 'blue'",
+		"unset key",
+		"plot $G1 title \"A\"  with points",
+	}
+	if got := backend.Commands(); !slices.Equal(got[len(got)-len(want):], want) {
+		t.Errorf("Wrong commands:\n got %q\nwant %q", got, want)
+	}
+}
+
+func TestParseSetting(t *testing.T) {
+	tests := []struct {
+		cmd  string
+		want setting
+		ok   bool
+	}{
+		{"set title 'a  b'", setting{name: "title", value: "'a  b'"}, true},
+		{"unset key", setting{name: "key", unset: true}, true},
+		{"set label 3 'x' at 1,2", setting{name: "label 3", value: "'x' at 1,2"}, true},
+		{"set label 'x' at 1,2", setting{name: "label", value: "'x' at 1,2"}, true},
+		{"set style data lines", setting{name: "style data", value: "lines"}, true},
+		{"set style line 2 lw 2", setting{name: "style line 2", value: "lw 2"}, true},
+		{"plot sin(x)", setting{}, false},
+		{"set", setting{}, false},
+	}
+	for _, test := range tests {
+		got, ok := parseSetting(test.cmd)
+		if got != test.want || ok != test.ok {
+			t.Errorf("parseSetting(%q) = %+v, %v, want %+v, %v", test.cmd, got, ok, test.want, test.ok)
+		}
+	}
+}
+
+func TestRenderCmdTerminal(t *testing.T) {
+	backend := NewRecordingBackend()
+	plot, _ := NewPlotWithOptions(2, WithBackend(backend), WithTerminal("dumb"),
+		WithTransport(TransportDatablock))
+	plot.Cmd("set term pngcairo size 800,600")
+	plot.Cmd("set o 'live.png'")
+	plot.AddPointGroup("A", "points", []int{1, 2})
+	backend.Reset()
+	if err := plot.SavePlot("saved.png"); err != nil {
+		t.Fatal(err)
+	}
+	plot.Render()
+	want := []string{
+		"set terminal png",
+		"set output 'saved.png'",
+		"replot  ",
+		"unset output",
+		"set terminal pngcairo size 800,600",
+		"reset",
+		"plot $G1 title \"A\"  with points",
+	}
+	if got := backend.Commands(); !slices.Equal(got, want) {
+		t.Errorf("Wrong commands:\n got %q\nwant %q", got, want)
+	}
+	if plot.Terminal() != "pngcairo size 800,600" {
+		t.Errorf("Expected the terminal set with Cmd, got %q", plot.Terminal())
+	}
+}
@@ -0,0 +1,143 @@
+package glot
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// ErrNotRunning is returned for commands sent to a plot whose gnuplot
+// process exited, unless the plot was made WithAutoRestart.
+var ErrNotRunning = errors.New("glot: gnuplot is not running")
+
+// Commands besides settings that change gnuplot's state and are
+// replayed after a restart, along with definitions of variables and
+// functions.
+var (
+	replayedCommands = []string{"undefine", "load", "array"}
+	reDefinition     = regexp.MustCompile(`^\s*[A-Za-z_]\w*\s*(\([^)]*\))?\s*=`)
+)
+
+// record remembers a command sent with Cmd: settings become part of the
+// plot and are applied on every render, definitions are replayed after
+// a restart.
+func (plot *Plot) record(cmd string) {
+	if s, ok := parseSetting(cmd); ok {
+		switch {
+		case isOption(s.name, "terminal", 1):
+			// a reset keeps the terminal, it's restored by SavePlot
+			// and a restart instead of being set on every render
+			if !s.unset && s.value != "" && s.value != "push" && s.value != "pop" {
+				plot.terminal = s.value
+			}
+		case isOption(s.name, "output", 1):
+			// the output belongs to the terminal, SavePlot sets its own
+		default:
+			plot.putSetting(s)
+		}
+		return
+	}
+	fields := strings.Fields(cmd)
+	if len(fields) == 0 {
+		return
+	}
+	if fields[0] == "reset" {
+		plot.settings = nil
+		return
+	}
+	if !slices.Contains(replayedCommands, fields[0]) && !reDefinition.MatchString(cmd) {
+		return
+	}
+	// a later definition of the same variable supersedes it
+	key := historyKey(cmd)
+	plot.history = slices.DeleteFunc(plot.history, func(c string) bool { return historyKey(c) == key })
+	plot.history = append(plot.history, cmd)
+}
+
+// isOption reports whether name is option or one of its abbreviations
+// of at least n characters, as accepted by gnuplot.
+func isOption(name, option string, n int) bool {
+	return len(name) >= n && strings.HasPrefix(option, name)
+}
+
+// historyKey identifies what a recorded command changes: the name of a
+// definition, or else the whole command.
+func historyKey(cmd string) string {
+	if m := reDefinition.FindString(cmd); m != "" {
+		name, _, _ := strings.Cut(m, "(")
+		return strings.TrimSpace(strings.TrimSuffix(name, "="))
+	}
+	return cmd
+}
+
+// Alive reports whether gnuplot is still running. It is always true for
+// backends that can't tell.
+func (plot *Plot) Alive() bool {
+	if plot.closed.Load() {
+		return false
+	}
+	if b, ok := plot.backend.(interface{ Alive() bool }); ok {
+		return b.Alive()
+	}
+	return true
+}
+
+// ensureAlive restarts gnuplot if it exited and the plot was made
+// WithAutoRestart.
+func (plot *Plot) ensureAlive(ctx context.Context) error {
+	if plot.Alive() {
+		return nil
+	}
+	if plot.closed.Load() || !plot.autoRestart || plot.restarting {
+		// a gnuplot that exits while it's restored isn't restarted
+		// again, it would most likely exit the same way
+		return ErrNotRunning
+	}
+	return plot.restart(ctx)
+}
+
+// Restart starts a new gnuplot process, e.g. after the previous one
+// crashed, and restores the plot: the terminal, every setting made
+// through Cmd and the setters and all PointGroups. Plots using a custom
+// Backend can't be restarted.
+func (plot *Plot) Restart() error {
+	return plot.restart(context.Background())
+}
+
+func (plot *Plot) restart(ctx context.Context) error {
+	if plot.closed.Load() {
+		return ErrNotRunning
+	}
+	if plot.newBackend == nil {
+		return &gnuplotError{"a plot with a custom backend can't be restarted"}
+	}
+	plot.restarting = true
+	defer func() { plot.restarting = false }()
+	// reclaim what is left of the old process
+	plot.backend.Close(ctx)
+	backend, err := plot.newBackend()
+	if err != nil {
+		return err
+	}
+	plot.backend = backend
+	plot.nplots = 0
+	for _, pointGroup := range plot.PointGroup {
+		// the datablocks died with gnuplot, the files are still there
+		if pointGroup.block != "" {
+			pointGroup.block = ""
+			pointGroup.source = ""
+			pointGroup.using = ""
+		}
+	}
+	if err := plot.cmd(ctx, "set term %s", plot.terminal); err != nil {
+		return err
+	}
+	for _, cmd := range plot.history {
+		if err := plot.cmd(ctx, "%s", cmd); err != nil {
+			return err
+		}
+	}
+	return plot.RenderContext(ctx)
+}
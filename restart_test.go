@@ -0,0 +1,86 @@
+package glot
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestAutoRestart(t *testing.T) {
+	b1 := NewRecordingBackend()
+	plot, err := NewPlotWithOptions(2, WithBackend(b1), WithTerminal("dumb"),
+		WithTransport(TransportDatablock), WithAutoRestart())
+	if err != nil {
+		t.Fatal(err)
+	}
+	b2 := NewRecordingBackend()
+	plot.newBackend = func() (Backend, error) { return b2, nil }
+	plot.SetTitle("Old")
+	plot.Cmd("a = 2")
+	plot.SetTitle("New")
+	plot.Cmd("plot 1")
+	plot.AddPointGroup("Sample", "points", []int{3, 4})
+
+	// simulate a crash
+	b1.Close(t.Context())
+	if plot.Alive() {
+		t.Fatal("Expected the plot to notice the closed backend")
+	}
+	if err := plot.SetXLabel("x"); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"set term dumb",
+		"a = 2",
+		"$G2 << EOD",
+		"3",
+		"4",
+		"EOD",
+		"reset",
+		"set title \"New\"",
+		"set xlabel 'x'",
+		"plot $G2 title \"Sample\"  with points",
+	}
+	if got := b2.Commands(); !slices.Equal(got, want) {
+		t.Errorf("Wrong commands:\n got %q\nwant %q", got, want)
+	}
+	if !plot.Alive() {
+		t.Error("Expected the plot to be alive after the restart")
+	}
+}
+
+func TestNotRunning(t *testing.T) {
+	backend := NewRecordingBackend()
+	plot, _ := NewPlotWithBackend(2, backend, false)
+	backend.Close(t.Context())
+	if err := plot.SetXLabel("x"); !errors.Is(err, ErrNotRunning) {
+		t.Errorf("Expected ErrNotRunning, got %v", err)
+	}
+	if err := plot.Restart(); err == nil {
+		t.Error("Expected a plot with a custom backend not to restart")
+	}
+}
+
+func TestRestartDeadBackend(t *testing.T) {
+	backend := NewRecordingBackend()
+	plot, _ := NewPlotWithOptions(2, WithBackend(backend), WithTerminal("dumb"), WithAutoRestart())
+	plot.AddPointGroup("Sample", "points", []int{3, 4})
+	started := 0
+	plot.newBackend = func() (Backend, error) {
+		// a gnuplot that exits right away, e.g. on a bad terminal
+		started++
+		dead := NewRecordingBackend()
+		dead.Close(t.Context())
+		return dead, nil
+	}
+	backend.Close(t.Context())
+	if err := plot.SetXLabel("x"); !errors.Is(err, ErrNotRunning) {
+		t.Errorf("Expected ErrNotRunning, got %v", err)
+	}
+	if err := plot.Restart(); !errors.Is(err, ErrNotRunning) {
+		t.Errorf("Expected ErrNotRunning, got %v", err)
+	}
+	if started != 2 {
+		t.Errorf("Expected one start per restart, got %d", started)
+	}
+}
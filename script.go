@@ -0,0 +1,70 @@
+package glot
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// SaveScript writes a standalone gnuplot script that draws the plot,
+// so it can be re-rendered with plain gnuplot, e.g. with
+// `gnuplot figure.gp`. The script isn't a log of the commands sent so
+// far but the current state of the plot: the terminal, the definitions
+// sent with Cmd, the data of all PointGroups as datablocks and the
+// commands that render the plot, as Render sends them. With an
+// interactive terminal the script waits until the window is closed;
+// otherwise the plot is written to stdout unless the commented `set
+// output` line is filled in.
+//
+// Usage
+//
+//	f, _ := os.Create("figure.gp")
+//	defer f.Close()
+//	plot.SaveScript(f)
+func (plot *Plot) SaveScript(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "# gnuplot script written by glotter")
+	fmt.Fprintf(bw, "set term %s\n", plot.terminal)
+	term, _, _ := strings.Cut(plot.terminal, " ")
+	interactive := slices.Contains(interactiveTerminals, term)
+	if !interactive {
+		fmt.Fprintln(bw, "# set output \"figure\"")
+	}
+	for _, cmd := range plot.history {
+		fmt.Fprintln(bw, cmd)
+	}
+	blocks := make(map[*PointGroup]string)
+	for i, pointGroup := range plot.groups() {
+		if pointGroup.function != "" {
+			continue
+		}
+		name := fmt.Sprintf("$G%d", i+1)
+		fmt.Fprintf(bw, "%s << EOD\n", name)
+		if err := plot.writeGroupColumns(bw, pointGroup, pointGroup.columnData()); err != nil {
+			return err
+		}
+		fmt.Fprintln(bw, "EOD")
+		blocks[pointGroup] = name
+	}
+	script, err := plot.script(func(pointGroup *PointGroup) (string, error) {
+		if pointGroup.function != "" {
+			return pointGroup.function, nil
+		}
+		if c, ok := pointGroup.data.(Categories); ok {
+			return blocks[pointGroup] + " using " + c.using(pointGroup.style), nil
+		}
+		return blocks[pointGroup], nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, cmd := range script {
+		fmt.Fprintln(bw, cmd)
+	}
+	if interactive {
+		fmt.Fprintln(bw, "pause mouse close")
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,54 @@
+package glot
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSaveScript(t *testing.T) {
+	plot, _ := NewPlotWithOptions(2, WithBackend(NewRecordingBackend()), WithTerminal("dumb"),
+		WithTempDir(t.TempDir()))
+	plot.Cmd("f(x) = 2*x")
+	plot.SetTitle("Test plot")
+	plot.AddPointGroup("Sample1", "points", []float64{3, 4.5})
+	plot.AddPointGroup("Sample2", "lines", [][]int{{1, 2}, {5, 6}})
+	plot.addFunction(t.Context(), "Line", "lines", "f(x)", nil)
+	plot.Cmd("set key left")
+	var sb strings.Builder
+	if err := plot.SaveScript(&sb); err != nil {
+		t.Fatal(err)
+	}
+	want := `# gnuplot script written by glotter
+set term dumb
+# set output "figure"
+f(x) = 2*x
+$G1 << EOD
+3
+4.5
+EOD
+$G2 << EOD
+1 5
+2 6
+EOD
+reset
+set title "Test plot"
+set key left
+plot $G1 title "Sample1"  with points, $G2 title "Sample2"  with lines, f(x) title "Line"  with lines
+`
+	if got := sb.String(); got != want {
+		t.Errorf("Wrong script:\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestSaveScriptInteractive(t *testing.T) {
+	plot, _ := NewPlotWithOptions(2, WithBackend(NewRecordingBackend()), WithTerminal("wxt enhanced"))
+	plot.AddPointGroup("Sample1", "points", []float64{3})
+	var sb strings.Builder
+	if err := plot.SaveScript(&sb); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(sb.String()), "\n")
+	if lines[1] != "set term wxt enhanced" || lines[len(lines)-1] != "pause mouse close" {
+		t.Errorf("Expected the script to set the terminal and wait for the window, got\n%s", sb.String())
+	}
+}
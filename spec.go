@@ -0,0 +1,375 @@
+package glot
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Spec is a serializable description of a plot, from which the plot
+// can be rebuilt exactly, see Plot.MarshalSpec and LoadSpec.
+type Spec struct {
+	Dimensions  int              `json:"dimensions"`
+	Format      string           `json:"format,omitempty"`
+	TimeZone    string           `json:"timeZone,omitempty"` // zone of the TimeSeries, see Plot.SetTimeZone
+	Settings    []SettingSpec    `json:"settings,omitempty"`
+	PointGroups []PointGroupSpec `json:"pointGroups,omitempty"`
+	Annotations []AnnotationSpec `json:"annotations,omitempty"`
+}
+
+// AnnotationSpec is an annotation added with Plot.AddAnnotation and its
+// ID. Exactly one of the fields after the ID is set. The annotation is
+// drawn by its setting, which is part of the Settings of the Spec.
+type AnnotationSpec struct {
+	ID        int        `json:"id"`
+	Label     *Label     `json:"label,omitempty"`
+	Arrow     *Arrow     `json:"arrow,omitempty"`
+	Rectangle *Rectangle `json:"rectangle,omitempty"`
+	Circle    *Circle    `json:"circle,omitempty"`
+	Ellipse   *Ellipse   `json:"ellipse,omitempty"`
+	Polygon   *Polygon   `json:"polygon,omitempty"`
+}
+
+// annotation returns the annotation of the spec.
+func (a AnnotationSpec) annotation() (Annotation, error) {
+	var annotations []Annotation
+	if a.Label != nil {
+		annotations = append(annotations, *a.Label)
+	}
+	if a.Arrow != nil {
+		annotations = append(annotations, *a.Arrow)
+	}
+	if a.Rectangle != nil {
+		annotations = append(annotations, *a.Rectangle)
+	}
+	if a.Circle != nil {
+		annotations = append(annotations, *a.Circle)
+	}
+	if a.Ellipse != nil {
+		annotations = append(annotations, *a.Ellipse)
+	}
+	if a.Polygon != nil {
+		annotations = append(annotations, *a.Polygon)
+	}
+	if len(annotations) != 1 {
+		return nil, &gnuplotError{fmt.Sprintf("the annotation %d must be exactly one of label, arrow, rectangle, circle, ellipse and polygon", a.ID)}
+	}
+	return annotations[0], nil
+}
+
+// SettingSpec is a gnuplot option of a plot, applied with "set <name>
+// <value>" or, if Unset, with "unset <name>". The title, labels and
+// ranges are the settings "title", "xlabel", "xrange" and so on.
+type SettingSpec struct {
+	Name  string `json:"name"`
+	Value string `json:"value,omitempty"`
+	Unset bool   `json:"unset,omitempty"`
+}
+
+// PointGroupSpec describes a PointGroup. Exactly one of Values,
+// Columns, Categories, TimeSeries, File and Function holds its data.
+type PointGroupSpec struct {
+	Name       string            `json:"name"`
+	Style      string            `json:"style"`
+	Values     []float64         `json:"values,omitempty"`     // one-dimensional data
+	Columns    [][]float64       `json:"columns,omitempty"`    // multi-dimensional data, one slice per column
+	Categories *Categories       `json:"categories,omitempty"` // labeled data
+	TimeSeries *TimeSeries       `json:"timeSeries,omitempty"` // values over time
+	File       string            `json:"file,omitempty"`       // text file with one point per line, read when loading
+	Function   string            `json:"function,omitempty"`   // gnuplot expression, e.g. a fitted curve
+	Styles     []PlotObjectStyle `json:"styles,omitempty"`
+}
+
+// MarshalJSON writes the data of the PointGroup with NaN and infinite
+// values as strings, see specFloat.
+func (g PointGroupSpec) MarshalJSON() ([]byte, error) {
+	type plain PointGroupSpec
+	return json.Marshal(struct {
+		plain
+		Values  []specFloat   `json:"values,omitempty"`
+		Columns [][]specFloat `json:"columns,omitempty"`
+	}{plain(g), convertFloats[specFloat](g.Values), convertColumns[specFloat](g.Columns)})
+}
+
+// UnmarshalJSON reads a PointGroup written by MarshalJSON.
+func (g *PointGroupSpec) UnmarshalJSON(data []byte) error {
+	type plain PointGroupSpec
+	var v struct {
+		plain
+		Values  []specFloat   `json:"values,omitempty"`
+		Columns [][]specFloat `json:"columns,omitempty"`
+	}
+	if err := decodeStrict(data, &v); err != nil {
+		return err
+	}
+	*g = PointGroupSpec(v.plain)
+	g.Values = convertFloats[float64](v.Values)
+	g.Columns = convertColumns[float64](v.Columns)
+	return nil
+}
+
+// specFloat is a value of a spec. JSON numbers can't be NaN or
+// infinite, such values are written as the strings "NaN", "+Inf" and
+// "-Inf" instead.
+type specFloat float64
+
+func (f specFloat) MarshalJSON() ([]byte, error) {
+	v := float64(f)
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return json.Marshal(strconv.FormatFloat(v, 'g', -1, 64))
+	}
+	return json.Marshal(v)
+}
+
+func (f *specFloat) UnmarshalJSON(data []byte) error {
+	if len(data) == 0 || data[0] != '"' {
+		return json.Unmarshal(data, (*float64)(f))
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil || !math.IsNaN(v) && !math.IsInf(v, 0) {
+		return &gnuplotError{fmt.Sprintf("invalid value %s, expected a number, \"NaN\", \"+Inf\" or \"-Inf\"", data)}
+	}
+	*f = specFloat(v)
+	return nil
+}
+
+func convertFloats[U, T ~float64](values []T) []U {
+	if values == nil {
+		return nil
+	}
+	converted := make([]U, len(values))
+	for i, v := range values {
+		converted[i] = U(v)
+	}
+	return converted
+}
+
+func convertColumns[U, T ~float64](columns [][]T) [][]U {
+	if columns == nil {
+		return nil
+	}
+	converted := make([][]U, len(columns))
+	for i, column := range columns {
+		converted[i] = convertFloats[U](column)
+	}
+	return converted
+}
+
+// decodeStrict decodes JSON like LoadSpec, rejecting unknown fields,
+// which custom UnmarshalJSON methods would otherwise accept.
+func decodeStrict(data []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	return dec.Decode(v)
+}
+
+// Spec returns the description of the plot. It shares the data slices
+// of the PointGroups, which are embedded rather than referenced by file.
+func (plot *Plot) Spec() *Spec {
+	spec := &Spec{Dimensions: plot.dimensions, Format: plot.format}
+	if plot.timeLocation != nil {
+		spec.TimeZone = plot.timeLocation.String()
+	}
+	for _, s := range plot.settings {
+		spec.Settings = append(spec.Settings, SettingSpec{Name: s.name, Value: s.value, Unset: s.unset})
+	}
+	for _, pointGroup := range plot.groups() {
+		g := PointGroupSpec{Name: pointGroup.name, Style: pointGroup.style,
+			Function: pointGroup.function, Styles: slices.Clone(pointGroup.plotObjectStyles)}
+		switch d := pointGroup.castedData.(type) {
+		case []float64:
+			g.Values = d
+		case [][]float64:
+			g.Columns = d
+		}
+		switch d := pointGroup.data.(type) {
+		case Categories:
+			g.Columns, g.Categories = nil, &d
+		case TimeSeries:
+			// the columns hold seconds since the epoch
+			g.Columns, g.TimeSeries = nil, &d
+		}
+		spec.PointGroups = append(spec.PointGroups, g)
+	}
+	for id, a := range plot.Annotations() {
+		s := AnnotationSpec{ID: id}
+		switch a := a.(type) {
+		case Label:
+			s.Label = &a
+		case Arrow:
+			s.Arrow = &a
+		case Rectangle:
+			s.Rectangle = &a
+		case Circle:
+			s.Circle = &a
+		case Ellipse:
+			s.Ellipse = &a
+		case Polygon:
+			s.Polygon = &a
+		}
+		spec.Annotations = append(spec.Annotations, s)
+	}
+	return spec
+}
+
+// MarshalSpec returns the description of the plot as JSON, which
+// LoadSpec turns back into a plot.
+//
+// Usage
+//
+//	spec, _ := plot.MarshalSpec()
+//	os.WriteFile("figure.json", spec, 0o644)
+func (plot *Plot) MarshalSpec() ([]byte, error) {
+	return json.MarshalIndent(plot.Spec(), "", "  ")
+}
+
+// LoadSpec makes a new plot from a JSON description written by
+// MarshalSpec. The options configure the new plot like with
+// NewPlotWithOptions. Data files referenced by the PointGroups are
+// read relative to the working directory.
+//
+// Usage
+//
+//	f, _ := os.Open("figure.json")
+//	defer f.Close()
+//	plot, _ := glot.LoadSpec(f, glot.WithTerminal("pngcairo"))
+//	plot.SavePlot("figure.png")
+func LoadSpec(r io.Reader, options ...PlotOption) (*Plot, error) {
+	var spec Spec
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&spec); err != nil {
+		return nil, err
+	}
+	return NewPlotFromSpec(&spec, options...)
+}
+
+// NewPlotFromSpec makes a new plot from its description. The options
+// configure the new plot like with NewPlotWithOptions.
+func NewPlotFromSpec(spec *Spec, options ...PlotOption) (*Plot, error) {
+	plot, err := NewPlotWithOptions(spec.Dimensions, options...)
+	if err != nil {
+		return nil, err
+	}
+	if err := plot.applySpec(spec); err != nil {
+		plot.Close()
+		return nil, err
+	}
+	return plot, nil
+}
+
+func (plot *Plot) applySpec(spec *Spec) error {
+	if spec.Format != "" {
+		if err := plot.SetFormat(spec.Format); err != nil {
+			return err
+		}
+	}
+	if spec.TimeZone != "" {
+		loc, err := time.LoadLocation(spec.TimeZone)
+		if err != nil {
+			return err
+		}
+		plot.timeLocation = loc
+	}
+	for _, s := range spec.Settings {
+		plot.putSetting(setting{name: s.Name, value: s.Value, unset: s.Unset})
+	}
+	for _, s := range spec.Annotations {
+		a, err := s.annotation()
+		if err != nil {
+			return err
+		}
+		// the settings already draw the annotation
+		if plot.annotations == nil {
+			plot.annotations = make(map[int]Annotation)
+		}
+		plot.annotations[s.ID] = a
+	}
+	if len(spec.PointGroups) == 0 {
+		return plot.Render()
+	}
+	for _, g := range spec.PointGroups {
+		if err := plot.addSpecGroup(g); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (plot *Plot) addSpecGroup(g PointGroupSpec) error {
+	switch {
+	case g.Function != "":
+		return plot.addFunction(context.Background(), g.Name, g.Style, g.Function, g.Styles)
+	case g.File != "":
+		data, err := readDataFile(g.File)
+		if err != nil {
+			return err
+		}
+		return plot.AddPointGroup(g.Name, g.Style, data, g.Styles...)
+	case g.TimeSeries != nil:
+		return plot.AddPointGroup(g.Name, g.Style, *g.TimeSeries, g.Styles...)
+	case g.Categories != nil:
+		return plot.AddPointGroup(g.Name, g.Style, *g.Categories, g.Styles...)
+	case g.Columns != nil:
+		return plot.AddPointGroup(g.Name, g.Style, g.Columns, g.Styles...)
+	case g.Values != nil:
+		return plot.AddPointGroup(g.Name, g.Style, g.Values, g.Styles...)
+	}
+	return &gnuplotError{fmt.Sprintf("the PointGroup %s has no data", g.Name)}
+}
+
+// readDataFile reads a text data file with one point per line and the
+// values separated by whitespace. Empty lines and comments starting
+// with # are skipped. A file with a single column is read as
+// one-dimensional data.
+func readDataFile(name string) (any, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var columns [][]float64
+	scanner := bufio.NewScanner(f)
+	for lineno := 1; scanner.Scan(); lineno++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		if columns == nil {
+			columns = make([][]float64, len(fields))
+		}
+		if len(fields) != len(columns) {
+			return nil, &gnuplotError{fmt.Sprintf("%s:%d: expected %d values, found %d", name, lineno, len(columns), len(fields))}
+		}
+		for i, field := range fields {
+			v, err := strconv.ParseFloat(field, 64)
+			if err != nil {
+				return nil, &gnuplotError{fmt.Sprintf("%s:%d: %v", name, lineno, err)}
+			}
+			columns[i] = append(columns[i], v)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(columns) == 0 {
+		return nil, &gnuplotError{fmt.Sprintf("%s: no data", name)}
+	}
+	if len(columns) == 1 {
+		return columns[0], nil
+	}
+	return columns, nil
+}
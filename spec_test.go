@@ -0,0 +1,139 @@
+package glot
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestSpecRoundTrip(t *testing.T) {
+	backend := NewRecordingBackend()
+	plot, _ := NewPlotWithOptions(2, WithBackend(backend), WithTerminal("dumb"),
+		WithTransport(TransportDatablock))
+	plot.SetTitle("Test plot")
+	plot.SetLabels("X", "Y")
+	plot.SetXrange(-2, 2)
+	plot.SetFormat("pdf")
+	plot.Cmd("unset key")
+	style := NewPlotObjectStyle(SetPointType(7), SetLineColor("rgb", "red"))
+	plot.AddPointGroup("Sample1", "points", []float64{3, math.NaN(), 5, math.Inf(1)})
+	plot.AddPointGroup("Sample2", "yerrorbars", [][]float64{{1, 2}, {0.1, math.Inf(-1)}, {0.5, 0.25}}, *style)
+	plot.AddPointGroup("Requests", "boxes", Categories{Labels: []string{"api", "auth"}, Values: []float64{math.NaN(), 2}})
+	plot.addFunction(t.Context(), "Fit", "lines", "2*x+1", nil)
+	plot.AddAnnotation(Label{Text: "peak", At: First(1, 4), Style: *style})
+	arrow, _ := plot.AddAnnotation(Arrow{From: Graph(0, 0), To: Graph(1, 1), Heads: "nohead"})
+	plot.AddAnnotation(Polygon{Vertices: []Position{Screen(0, 0), Screen(1, 0), Screen(0, 1)}, Fill: "solid 0.2"})
+	spec, err := plot.MarshalSpec()
+	if err != nil {
+		t.Fatal(err)
+	}
+	backend.Reset()
+	plot.Render()
+
+	loadedBackend := NewRecordingBackend()
+	loaded, err := LoadSpec(bytes.NewReader(spec), WithBackend(loadedBackend), WithTerminal("dumb"),
+		WithTransport(TransportDatablock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, _ := loaded.MarshalSpec()
+	if !bytes.Equal(spec, again) {
+		t.Errorf("Spec changed after a round trip:\n%s\n%s", spec, again)
+	}
+	loadedBackend.Reset()
+	loaded.Render()
+	if got, want := loadedBackend.Commands(), backend.Commands(); !slices.Equal(got, want) {
+		t.Errorf("Loaded plot renders differently:\n got %q\nwant %q", got, want)
+	}
+	var ids []int
+	for id := range loaded.Annotations() {
+		ids = append(ids, id)
+	}
+	if want := []int{1, 2, 3}; !slices.Equal(ids, want) {
+		t.Errorf("Wrong annotations after loading: got %v, want %v", ids, want)
+	}
+	if err := loaded.UpdateAnnotation(arrow, Arrow{From: Graph(0, 1), To: Graph(1, 0)}); err != nil {
+		t.Error(err)
+	}
+	if err := loaded.RemoveAnnotation(arrow); err != nil {
+		t.Error(err)
+	}
+	if loaded.format != "pdf" {
+		t.Errorf("Expected format pdf, got %s", loaded.format)
+	}
+}
+
+func TestLoadSpecFile(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "data.txt")
+	os.WriteFile(name, []byte("# x y\n1 2\n\n3 4\n"), 0o644)
+	spec := `{"dimensions": 2, "pointGroups": [{"name": "Data", "style": "lines", "file": "` + name + `"}]}`
+	plot, err := LoadSpec(bytes.NewReader([]byte(spec)), WithBackend(NewRecordingBackend()), WithTerminal("dumb"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := plot.PointGroup["Data"].castedData.([][]float64)
+	if want := [][]float64{{1, 3}, {2, 4}}; !slices.EqualFunc(got, want, slices.Equal) {
+		t.Errorf("Wrong data %v, want %v", got, want)
+	}
+
+	for _, bad := range []string{
+		`{"dimensions": 2, "pointGroups": [{"name": "Data", "style": "lines"}]}`,
+		`{"dimensions": 2, "pointGroups": [{"name": "Data", "style": "lines", "file": "missing.txt"}]}`,
+		`{"dimensions": 2, "title": "unknown field"}`,
+		`{"dimensions": 4}`,
+		`{"dimensions": 2, "annotations": [{"id": 1}]}`,
+	} {
+		if _, err := LoadSpec(bytes.NewReader([]byte(bad)), WithBackend(NewRecordingBackend()), WithTerminal("dumb")); err == nil {
+			t.Errorf("Expected an error loading %s", bad)
+		}
+	}
+}
+
+func TestSpecTimeSeries(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip(err)
+	}
+	options := []PlotOption{WithTerminal("dumb"), WithTransport(TransportDatablock), WithPrecision(3)}
+	plot, _ := NewPlotWithOptions(2, append(options, WithBackend(NewRecordingBackend()))...)
+	plot.SetTimeZone(berlin)
+	start := time.Date(2024, 7, 1, 0, 30, 0, 250_000_000, time.UTC)
+	times := []time.Time{start, start.Add(2 * time.Hour)}
+	plot.AddTimeSeries("cpu", "lines", times, []float64{0.5, math.NaN()})
+	spec, err := plot.MarshalSpec()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	backend := NewRecordingBackend()
+	loaded, err := LoadSpec(bytes.NewReader(spec), append(options, WithBackend(backend))...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, _ := loaded.MarshalSpec()
+	if !bytes.Equal(spec, again) {
+		t.Errorf("Spec changed after a round trip:\n%s\n%s", spec, again)
+	}
+	if loaded.timeLocation.String() != "Europe/Berlin" {
+		t.Errorf("Expected the time zone Europe/Berlin, got %v", loaded.timeLocation)
+	}
+	backend.Reset()
+	if err := loaded.SetTimeZone(time.UTC); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"undefine $G1",
+		"$G2 << EOD",
+		fmt.Sprintf("%d.25 0.5", start.Unix()),
+		fmt.Sprintf("%d.25 NaN", start.Add(2*time.Hour).Unix()),
+		"EOD",
+	}
+	if got := backend.Commands(); !slices.Equal(got[:len(want)], want) {
+		t.Errorf("Wrong commands:\n got %q\nwant %q", got, want)
+	}
+}
@@ -0,0 +1,113 @@
+package glot
+
+import (
+	"context"
+	"fmt"
+)
+
+// ColumnStats holds the statistics of one column of data.
+type ColumnStats struct {
+	Mean        float64
+	StdDev      float64
+	Min         float64
+	Max         float64
+	IndexMin    int // index of the minimum, counting from 0
+	IndexMax    int // index of the maximum, counting from 0
+	LowQuartile float64
+	Median      float64
+	UpQuartile  float64
+}
+
+// Stats holds the statistics of a PointGroup as computed by gnuplot's
+// stats command, see Plot.Stats.
+type Stats struct {
+	Records int // number of valid points
+	Invalid int // number of invalid points, e.g. NaN values
+	// X holds the statistics of the first column of multi-dimensional
+	// data. It is empty for 1D data.
+	X ColumnStats
+	// Y holds the statistics of the second column of multi-dimensional
+	// data or of the values of 1D data.
+	Y ColumnStats
+	// The correlation and linear regression y = Slope*x + Intercept of
+	// multi-dimensional data. They are 0 for 1D data.
+	Correlation float64
+	Slope       float64
+	Intercept   float64
+}
+
+// statsPrefix is the prefix of the variables set by gnuplot's stats command.
+const statsPrefix = "GLOT_STATS"
+
+// Stats computes statistics of the data of a PointGroup with gnuplot's
+// stats command, so they match what gnuplot plots, e.g. with regard to
+// the ranges of the axes and invalid values. For multi-dimensional data
+// the first two columns are used.
+//
+// Usage
+//
+//	plot.AddPointGroup("Sample1", "points", [][]float64{{1, 2, 3, 4}, {2.1, 3.9, 6.2, 7.8}})
+//	stats, _ := plot.Stats("Sample1")
+//	fmt.Println(stats.Y.Mean, stats.Slope)
+func (plot *Plot) Stats(name string) (Stats, error) {
+	return plot.StatsContext(context.Background(), name)
+}
+
+// StatsContext is like Stats but gives up once ctx is done. gnuplot is
+// killed in that case and ctx.Err() is returned.
+func (plot *Plot) StatsContext(ctx context.Context, name string) (Stats, error) {
+	var stats Stats
+	pointGroup, exists := plot.PointGroup[name]
+	if !exists {
+		return stats, &gnuplotError{fmt.Sprintf("A curve with name %s does not exist.", name)}
+	}
+	if pointGroup.source == "" {
+		return stats, &gnuplotError{fmt.Sprintf("The curve %s has no data.", name)}
+	}
+	_, nd := pointGroup.castedData.([][]float64)
+	using := "1"
+	if nd {
+		using = "1:2"
+	}
+	if err := plot.cmd(ctx, "stats %s using %s prefix \"%s\" nooutput",
+		pointGroup.source, using, statsPrefix); err != nil {
+		return stats, err
+	}
+
+	columnVars := []string{"mean", "stddev", "min", "max", "index_min", "index_max",
+		"lo_quartile", "median", "up_quartile"}
+	exprs := []string{statsPrefix + "_records", statsPrefix + "_invalid"}
+	if nd {
+		for _, suffix := range []string{"_x", "_y"} {
+			for _, v := range columnVars {
+				exprs = append(exprs, statsPrefix+"_"+v+suffix)
+			}
+		}
+		exprs = append(exprs, statsPrefix+"_correlation", statsPrefix+"_slope", statsPrefix+"_intercept")
+	} else {
+		for _, v := range columnVars {
+			exprs = append(exprs, statsPrefix+"_"+v)
+		}
+	}
+	values, err := plot.queryFloats(ctx, exprs...)
+	if err != nil {
+		return stats, err
+	}
+
+	stats.Records, stats.Invalid = int(values[0]), int(values[1])
+	values = values[2:]
+	column := func(values []float64) ColumnStats {
+		return ColumnStats{Mean: values[0], StdDev: values[1], Min: values[2], Max: values[3],
+			IndexMin: int(values[4]), IndexMax: int(values[5]),
+			LowQuartile: values[6], Median: values[7], UpQuartile: values[8]}
+	}
+	if nd {
+		n := len(columnVars)
+		stats.X = column(values[:n])
+		stats.Y = column(values[n : 2*n])
+		stats.Correlation, stats.Slope, stats.Intercept = values[2*n], values[2*n+1], values[2*n+2]
+	} else {
+		stats.Y = column(values)
+	}
+	return stats, nil
+}
@@ -0,0 +1,56 @@
+package glot
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	backend := NewRecordingBackend()
+	plot, _ := NewPlotWithOptions(2, WithBackend(backend), WithTerminal("dumb"),
+		WithTransport(TransportDatablock))
+	plot.AddPointGroup("Sample1", "points", []float64{4, 1, 3})
+	plot.AddPointGroup("Sample2", "points", [][]float64{{1, 2, 3}, {2, 4, 6}})
+	var stats []string
+	backend.Reply = func(input []string) (string, error) {
+		cmd := input[len(input)-1]
+		switch {
+		case strings.HasPrefix(cmd, "stats"):
+			stats = append(stats, cmd)
+		case strings.HasPrefix(cmd, "printerr GLOT_STATS_records, GLOT_STATS_invalid, GLOT_STATS_mean,"):
+			return "3 0 2.66667 1.24722 1 4 1 0 1 3 4", nil
+		case strings.HasPrefix(cmd, "printerr GLOT_STATS_records"):
+			return "3 1 2 0.816497 1 3 0 2 1 2 3 4 1.63299 2 6 0 2 2 4 6 1 2 0", nil
+		}
+		return "", nil
+	}
+
+	s, err := plot.Stats("Sample1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats[0] != `stats $G1 using 1 prefix "GLOT_STATS" nooutput` {
+		t.Errorf("Wrong stats command %q", stats[0])
+	}
+	want := ColumnStats{Mean: 2.66667, StdDev: 1.24722, Min: 1, Max: 4, IndexMin: 1, IndexMax: 0,
+		LowQuartile: 1, Median: 3, UpQuartile: 4}
+	if s.Records != 3 || s.Y != want {
+		t.Errorf("Wrong 1D stats: %+v", s)
+	}
+
+	s, err = plot.Stats("Sample2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats[1] != `stats $G2 using 1:2 prefix "GLOT_STATS" nooutput` {
+		t.Errorf("Wrong stats command %q", stats[1])
+	}
+	if s.Invalid != 1 || s.X.Mean != 2 || s.Y.Max != 6 || s.Y.IndexMax != 2 ||
+		s.Correlation != 1 || s.Slope != 2 || s.Intercept != 0 {
+		t.Errorf("Wrong ND stats: %+v", s)
+	}
+
+	if _, err := plot.Stats("Missing"); err == nil {
+		t.Error("Expected an error for a missing PointGroup")
+	}
+}
@@ -0,0 +1,70 @@
+package glot
+
+import (
+	"context"
+	"os"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+// TerminalEnv is the environment variable that overrides the terminal
+// chosen by NewPlot, e.g. GLOTTER_TERMINAL="pngcairo size 800,600".
+// A terminal set with WithTerminal takes precedence.
+const TerminalEnv = "GLOTTER_TERMINAL"
+
+// Interactive terminals in order of preference, only considered if a
+// display is available. Otherwise plots are drawn on dumb: file
+// terminals like pngcairo would write every render to stdout, as
+// nothing sets an output until SavePlot.
+var interactiveTerminals = []string{"wxt", "qt", "x11", "aqua", "windows"}
+
+// hasDisplay reports whether interactive terminals can open a window.
+func hasDisplay() bool {
+	switch runtime.GOOS {
+	case "windows", "darwin":
+		return true
+	}
+	return os.Getenv("DISPLAY") != "" || os.Getenv("WAYLAND_DISPLAY") != ""
+}
+
+// availableTerminals asks gnuplot for the terminals it was built with.
+func (plot *Plot) availableTerminals(ctx context.Context) ([]string, error) {
+	res, err := plot.checkedCmd(ctx, "printerr GPVAL_TERMINALS")
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(res), nil
+}
+
+// chooseTerminal returns the most suitable of the available terminals:
+// an interactive one if display is true, dumb otherwise.
+func chooseTerminal(available []string, display bool) string {
+	if display {
+		for _, term := range interactiveTerminals {
+			if slices.Contains(available, term) {
+				return term + " enhanced"
+			}
+		}
+	}
+	// dumb is part of every gnuplot build
+	return "dumb"
+}
+
+// detectTerminal picks the terminal of a new plot if none was configured.
+func (plot *Plot) detectTerminal(ctx context.Context) (string, error) {
+	if term := os.Getenv(TerminalEnv); term != "" {
+		return term, nil
+	}
+	available, err := plot.availableTerminals(ctx)
+	if err != nil {
+		return "", err
+	}
+	return chooseTerminal(available, hasDisplay()), nil
+}
+
+// Terminal returns the terminal the plot is displayed on, e.g.
+// "wxt enhanced" or "pngcairo".
+func (plot *Plot) Terminal() string {
+	return plot.terminal
+}
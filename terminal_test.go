@@ -0,0 +1,63 @@
+package glot
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestChooseTerminal(t *testing.T) {
+	tests := []struct {
+		available []string
+		display   bool
+		want      string
+	}{
+		{[]string{"dumb", "png", "pngcairo", "wxt", "x11"}, true, "wxt enhanced"},
+		{[]string{"dumb", "png", "pngcairo", "wxt", "x11"}, false, "dumb"},
+		{[]string{"dumb", "png", "qt"}, true, "qt enhanced"},
+		{[]string{"dumb", "png"}, true, "dumb"},
+		{[]string{}, false, "dumb"},
+	}
+	for _, test := range tests {
+		if got := chooseTerminal(test.available, test.display); got != test.want {
+			t.Errorf("chooseTerminal(%v, %v) = %q, want %q", test.available, test.display, got, test.want)
+		}
+	}
+}
+
+func TestDetectTerminal(t *testing.T) {
+	t.Setenv("DISPLAY", "")
+	t.Setenv("WAYLAND_DISPLAY", "")
+	t.Setenv(TerminalEnv, "")
+	backend := NewRecordingBackend()
+	backend.Reply = func(input []string) (string, error) {
+		if slices.Contains(input, "printerr GPVAL_TERMINALS") {
+			return "dumb png pngcairo wxt", nil
+		}
+		return "", nil
+	}
+	plot, err := NewPlotWithOptions(2, WithBackend(backend))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !hasDisplay() {
+		if plot.Terminal() != "dumb" {
+			t.Errorf("Expected dumb without a display, got %q", plot.Terminal())
+		}
+	}
+	if got := backend.Commands(); got[len(got)-1] != "set term "+plot.Terminal() {
+		t.Errorf("Expected the chosen terminal to be set, got %q", got)
+	}
+}
+
+func TestTerminalEnv(t *testing.T) {
+	t.Setenv(TerminalEnv, "svg size 640,480")
+	backend := NewRecordingBackend()
+	plot, _ := NewPlotWithOptions(2, WithBackend(backend))
+	if plot.Terminal() != "svg size 640,480" {
+		t.Errorf("Expected the terminal from %s, got %q", TerminalEnv, plot.Terminal())
+	}
+	plot, _ = NewPlotWithOptions(2, WithBackend(backend), WithTerminal("dumb"))
+	if plot.Terminal() != "dumb" {
+		t.Errorf("Expected WithTerminal to take precedence, got %q", plot.Terminal())
+	}
+}
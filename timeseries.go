@@ -0,0 +1,299 @@
+package glot
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TimeSeries is PointGroup data with times on the x-axis, passed to
+// AddPointGroup. The times are written as seconds since the epoch with
+// full sub-second precision and the plot is set up for time data, see
+// SetTimeFormat and SetTimeZone.
+//
+// Usage
+//
+//	plot.AddPointGroup("cpu", "lines", glot.TimeSeries{Times: times, Values: load})
+type TimeSeries struct {
+	Times  []time.Time `json:"times"`
+	Values []float64   `json:"values"`
+	Extra  [][]float64 `json:"extra,omitempty"` // further columns, e.g. the errors for yerrorbars
+}
+
+// MarshalJSON writes the time series with NaN and infinite values as
+// strings, like the data of a PointGroupSpec.
+func (ts TimeSeries) MarshalJSON() ([]byte, error) {
+	type plain TimeSeries
+	return json.Marshal(struct {
+		plain
+		Values []specFloat   `json:"values"`
+		Extra  [][]specFloat `json:"extra,omitempty"`
+	}{plain(ts), convertFloats[specFloat](ts.Values), convertColumns[specFloat](ts.Extra)})
+}
+
+// UnmarshalJSON reads a time series written by MarshalJSON.
+func (ts *TimeSeries) UnmarshalJSON(data []byte) error {
+	type plain TimeSeries
+	var v struct {
+		plain
+		Values []specFloat   `json:"values"`
+		Extra  [][]specFloat `json:"extra,omitempty"`
+	}
+	if err := decodeStrict(data, &v); err != nil {
+		return err
+	}
+	*ts = TimeSeries(v.plain)
+	ts.Values = convertFloats[float64](v.Values)
+	ts.Extra = convertColumns[float64](v.Extra)
+	return nil
+}
+
+// AddTimeSeries adds a PointGroup of values over time, see TimeSeries.
+//
+// Usage
+//
+//	plot.AddTimeSeries("cpu", "lines", times, load)
+//	plot.SetTimeFormat("%H:%M")
+func (plot *Plot) AddTimeSeries(name string, style string, times []time.Time, values []float64, spec ...PlotObjectStyle) error {
+	return plot.AddPointGroup(name, style, TimeSeries{Times: times, Values: values}, spec...)
+}
+
+// timeColumns converts a time series to columns of numbers, with the
+// times as seconds since the epoch.
+func (plot *Plot) timeColumns(ts TimeSeries) ([][]float64, error) {
+	if plot.dimensions != 2 {
+		return nil, &gnuplotError{"time series can only be plotted in 2D plots"}
+	}
+	if len(ts.Values) != len(ts.Times) {
+		return nil, &gnuplotError{fmt.Sprintf("%d times but %d values", len(ts.Times), len(ts.Values))}
+	}
+	for _, column := range ts.Extra {
+		if len(column) != len(ts.Times) {
+			return nil, &gnuplotError{fmt.Sprintf("%d times but %d values in an extra column", len(ts.Times), len(column))}
+		}
+	}
+	x := make([]float64, len(ts.Times))
+	for i, t := range ts.Times {
+		x[i] = float64(t.Unix()) + float64(t.Nanosecond())/1e9
+	}
+	return append([][]float64{x, ts.Values}, ts.Extra...), nil
+}
+
+// location returns the time zone times are shown in.
+func (plot *Plot) location() *time.Location {
+	if plot.timeLocation == nil {
+		return time.UTC
+	}
+	return plot.timeLocation
+}
+
+// timeAxis returns the offset of the time zone shared by all times of
+// the TimeSeries. gnuplot has no time zones, the times are shifted by
+// it to show the wall clock time. If the offset changes, e.g. because
+// the times span a DST change, varying is set and the times aren't
+// shifted, so they stay in order and the tics are labeled by timeTics.
+func (plot *Plot) timeAxis() (offset int, varying bool) {
+	loc := plot.location()
+	first := true
+	for _, pointGroup := range plot.PointGroup {
+		ts, ok := pointGroup.data.(TimeSeries)
+		if !ok {
+			continue
+		}
+		for _, t := range ts.Times {
+			_, o := t.In(loc).Zone()
+			if first {
+				offset, first = o, false
+			} else if o != offset {
+				return 0, true
+			}
+		}
+	}
+	return offset, false
+}
+
+// refreshTimeData releases the data of the TimeSeries if it was passed
+// to gnuplot with another offset than the current one, see timeAxis.
+func (plot *Plot) refreshTimeData(ctx context.Context) {
+	offset, _ := plot.timeAxis()
+	if offset == plot.timeOffset {
+		return
+	}
+	plot.timeOffset = offset
+	for _, pointGroup := range plot.PointGroup {
+		if _, ok := pointGroup.data.(TimeSeries); ok {
+			plot.releaseData(ctx, pointGroup)
+		}
+	}
+}
+
+// Steps between the tics placed by timeTics in seconds, steps of a day
+// and more follow the calendar.
+var timeTicSteps = []int64{1, 2, 5, 10, 15, 30, 60, 120, 300, 600, 900, 1800,
+	3600, 7200, 10800, 21600, 43200, 86400, 2 * 86400, 7 * 86400, 14 * 86400, 28 * 86400}
+
+// maxTimeTics is the most tics timeTics places.
+const maxTimeTics = 8
+
+// timeTics returns the command placing the tics of a time x-axis whose
+// times aren't shifted because the offset of the time zone changes,
+// see timeAxis. The tics are labeled with their wall clock times, so
+// the hour repeated when DST ends shows twice. Tics set with SetXTics
+// and Cmd are kept.
+func (plot *Plot) timeTics() (string, bool) {
+	if _, varying := plot.timeAxis(); !varying {
+		return "", false
+	}
+	format := ""
+	for _, s := range plot.settings {
+		switch {
+		case s.name == "xtics" && strings.Contains(s.value, "("):
+			return "", false
+		case s.name == "format x":
+			format, _ = strconv.QuotedPrefix(s.value)
+		}
+	}
+	var lo, hi time.Time
+	for _, pointGroup := range plot.PointGroup {
+		if ts, ok := pointGroup.data.(TimeSeries); ok {
+			for _, t := range ts.Times {
+				if lo.IsZero() || t.Before(lo) {
+					lo = t
+				}
+				if hi.IsZero() || t.After(hi) {
+					hi = t
+				}
+			}
+		}
+	}
+	span := hi.Sub(lo)
+	step := timeTicSteps[len(timeTicSteps)-1]
+	for _, s := range timeTicSteps {
+		if span <= time.Duration(s*maxTimeTics)*time.Second {
+			step = s
+			break
+		}
+	}
+	if format == "" {
+		switch {
+		case step < 60:
+			format = `"%H:%M:%S"`
+		case step >= 86400:
+			format = `"%Y-%m-%d"`
+		case span >= 24*time.Hour:
+			format = `"%m/%d %H:%M"`
+		default:
+			format = `"%H:%M"`
+		}
+	}
+	loc := plot.location()
+	var tics []string
+	add := func(t time.Time) {
+		_, offset := t.Zone()
+		tics = append(tics, fmt.Sprintf("strftime(%s, %d) %d", format, t.Unix()+int64(offset), t.Unix()))
+	}
+	if step >= 86400 {
+		days := int(step / 86400)
+		y, m, d := lo.In(loc).Date()
+		for t := time.Date(y, m, d, 0, 0, 0, 0, loc); !t.After(hi); t = time.Date(y, m, d, 0, 0, 0, 0, loc) {
+			if !t.Before(lo) {
+				add(t)
+			}
+			d += days
+		}
+	} else {
+		// align the tics to the wall clock, the offset is a multiple of
+		// the step for common zones
+		_, offset := lo.In(loc).Zone()
+		wall := lo.Unix() + int64(offset)
+		if lo.Nanosecond() > 0 {
+			wall++
+		}
+		first := (wall+step-1)/step*step - int64(offset)
+		for sec := first; sec <= hi.Unix(); sec += step {
+			add(time.Unix(sec, 0).In(loc))
+		}
+	}
+	if len(tics) == 0 {
+		return "", false
+	}
+	return "set xtics (" + strings.Join(tics, ", ") + ")", true
+}
+
+// putTimeSettings sets up the x-axis for time data.
+func (plot *Plot) putTimeSettings() {
+	plot.putSetting(setting{name: "xdata", value: "time"})
+	plot.putSetting(setting{name: "timefmt", value: "\"%s\""})
+}
+
+// dropTimeSettings removes the settings made by putTimeSettings once no
+// TimeSeries is left, so other data isn't plotted on a time x-axis.
+func (plot *Plot) dropTimeSettings() {
+	for _, pointGroup := range plot.PointGroup {
+		if _, ok := pointGroup.data.(TimeSeries); ok {
+			return
+		}
+	}
+	plot.settings = slices.DeleteFunc(plot.settings, func(s setting) bool {
+		return s == setting{name: "xdata", value: "time"} || s == setting{name: "timefmt", value: "\"%s\""}
+	})
+}
+
+// writeGroupColumns writes the data of a PointGroup as text, with the
+// times of a TimeSeries as exact seconds since the epoch, shifted to
+// the time zone, and the labels of Categories as a last, quoted column.
+func (plot *Plot) writeGroupColumns(w *bufio.Writer, pg *PointGroup, columns [][]float64) error {
+	var labels []string
+	if c, ok := pg.data.(Categories); ok {
+		labels = c.Labels
+	}
+	_, isTime := pg.data.(TimeSeries)
+	if offset, _ := plot.timeAxis(); isTime && offset != 0 {
+		x := make([]float64, len(columns[0]))
+		for i, v := range columns[0] {
+			x[i] = v + float64(offset)
+		}
+		columns = append([][]float64{x}, columns[1:]...)
+	}
+	return writeRows(w, columns, plot.precision, isTime, labels)
+}
+
+// SetTimeFormat sets the format of the tic labels of a time x-axis,
+// using the conversions of strftime, e.g. "%H:%M" or "%H:%M:%.3S" for
+// milliseconds.
+//
+// Usage
+//
+//	plot.SetTimeFormat("%Y-%m-%d\n%H:%M")
+func (plot *Plot) SetTimeFormat(format string) error {
+	return plot.set("format x", fmt.Sprintf("%q timedate", format))
+}
+
+// SetTimeZone sets the time zone the times of TimeSeries are shown in,
+// UTC by default. Times are shown as the wall clock time in that zone.
+// If the times span a change of the offset, e.g. when DST ends, they're
+// plotted in order and only the labels of the tics show the wall clock
+// time. These tics are placed once for all times and don't follow the
+// zoom of interactive terminals.
+//
+// Usage
+//
+//	loc, _ := time.LoadLocation("Europe/Berlin")
+//	plot.SetTimeZone(loc)
+func (plot *Plot) SetTimeZone(loc *time.Location) error {
+	prev := plot.timeLocation
+	plot.timeLocation = loc
+	if len(plot.PointGroup) == 0 {
+		return nil
+	}
+	if err := plot.Render(); err != nil {
+		plot.timeLocation = prev
+		return err
+	}
+	return nil
+}
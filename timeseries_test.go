@@ -0,0 +1,156 @@
+package glot
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimeSeries(t *testing.T) {
+	backend := NewRecordingBackend()
+	plot, _ := NewPlotWithOptions(2, WithBackend(backend), WithTerminal("dumb"),
+		WithTransport(TransportDatablock), WithPrecision(3))
+	start := time.Date(2024, 7, 1, 0, 30, 0, 250_000_000, time.UTC)
+	times := []time.Time{start, start.Add(2 * time.Hour)}
+	if err := plot.AddTimeSeries("cpu", "lines", times, []float64{0.5, 0.75}); err != nil {
+		t.Fatal(err)
+	}
+	plot.SetTimeFormat("%H:%M:%.3S")
+	backend.Reset()
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip(err)
+	}
+	if err := plot.SetTimeZone(berlin); err != nil {
+		t.Fatal(err)
+	}
+	// the times show as 02:30 and 04:30 CEST
+	wall := time.Date(2024, 7, 1, 2, 30, 0, 0, time.UTC).Unix()
+	want := []string{
+		"undefine $G1",
+		"$G2 << EOD",
+		fmt.Sprintf("%d.25 0.5", wall),
+		fmt.Sprintf("%d.25 0.75", wall+7200),
+		"EOD",
+		"reset",
+		"set xdata time",
+		"set timefmt \"%s\"",
+		"set format x \"%H:%M:%.3S\" timedate",
+		"plot $G2 title \"cpu\"  with lines",
+	}
+	if got := backend.Commands(); !slices.Equal(got, want) {
+		t.Errorf("Wrong commands:\n got %q\nwant %q", got, want)
+	}
+
+	ts := TimeSeries{Times: times, Values: []float64{1, 2}, Extra: [][]float64{{0.1, 0.2}}}
+	if err := plot.AddPointGroup("errors", "yerrorbars", ts); err != nil {
+		t.Fatal(err)
+	}
+	if err := plot.AddTimeSeries("short", "lines", times, []float64{1}); err == nil {
+		t.Error("Expected an error for a time series with fewer values than times")
+	}
+	plot3d, _ := NewPlotWithBackend(3, NewRecordingBackend(), false)
+	if err := plot3d.AddTimeSeries("cpu", "lines", times, []float64{1, 2}); err == nil {
+		t.Error("Expected time series to be rejected for 3D plots")
+	}
+	if len(plot3d.settings) != 0 {
+		t.Error("Expected no time settings for a rejected time series")
+	}
+}
+
+func TestTimeSeriesDSTEnd(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip(err)
+	}
+	backend := NewRecordingBackend()
+	plot, _ := NewPlotWithOptions(2, WithBackend(backend), WithTerminal("dumb"),
+		WithTransport(TransportDatablock))
+	plot.SetTimeZone(berlin)
+	// DST ends at 01:00 UTC, 02:00 to 03:00 is shown twice
+	start := time.Date(2024, 10, 26, 22, 0, 0, 0, time.UTC)
+	var times []time.Time
+	for i := range 13 {
+		times = append(times, start.Add(time.Duration(i)*30*time.Minute))
+	}
+	if err := plot.AddTimeSeries("p99", "lines", times, make([]float64, len(times))); err != nil {
+		t.Fatal(err)
+	}
+	got := backend.Commands()
+	for i := range times {
+		if want := fmt.Sprintf("%d 0", times[i].Unix()); got[i+2] != want {
+			t.Errorf("Expected the times in order as seconds since the epoch, got %q, want %q", got[i+2], want)
+		}
+	}
+	var tics []string
+	for i, label := range []string{"00:00", "01:00", "02:00", "02:00", "03:00", "04:00", "05:00"} {
+		wall, _ := time.Parse("15:04", label)
+		pos := start.Add(time.Duration(i) * time.Hour)
+		tics = append(tics, fmt.Sprintf("strftime(\"%%H:%%M\", %d) %d",
+			time.Date(2024, 10, 27, wall.Hour(), 0, 0, 0, time.UTC).Unix(), pos.Unix()))
+	}
+	want := "set xtics (" + strings.Join(tics, ", ") + ")"
+	if !slices.Contains(got, want) {
+		t.Errorf("Expected the tics\n%q\nin\n%q", want, got)
+	}
+
+	// tics set by the user are kept
+	plot.SetXTics(map[float64]string{float64(start.Unix()): "start"})
+	if got := backend.Commands(); slices.Contains(got[len(got)-3:], want) {
+		t.Error("Expected no tics to be placed over the tics set with SetXTics")
+	}
+}
+
+func TestRemoveTimeSeries(t *testing.T) {
+	backend := NewRecordingBackend()
+	plot, _ := NewPlotWithOptions(2, WithBackend(backend), WithTerminal("dumb"),
+		WithTransport(TransportDatablock))
+	times := []time.Time{time.Unix(1, 0), time.Unix(2, 0)}
+	plot.AddTimeSeries("cpu", "lines", times, []float64{1, 2})
+	plot.AddTimeSeries("mem", "lines", times, []float64{3, 4})
+	plot.AddPointGroup("load", "lines", []float64{5, 6})
+	plot.RemovePointGroup("cpu")
+	if !slices.Contains(plot.settings, setting{name: "xdata", value: "time"}) {
+		t.Error("Expected the time settings to stay while a time series is left")
+	}
+	backend.Reset()
+	plot.RemovePointGroup("mem")
+	want := []string{
+		"undefine $G2",
+		"reset",
+		"plot $G3 title \"load\"  with lines",
+	}
+	if got := backend.Commands(); !slices.Equal(got, want) {
+		t.Errorf("Wrong commands:\n got %q\nwant %q", got, want)
+	}
+
+	plot.AddTimeSeries("cpu", "lines", times, []float64{1, 2})
+	plot.ResetPlot()
+	if len(plot.settings) != 0 {
+		t.Errorf("Expected no time settings after ResetPlot, got %v", plot.settings)
+	}
+}
+
+func TestParseSettingFormat(t *testing.T) {
+	for cmd, want := range map[string]string{
+		"set format x \"%H\"": "format x",
+		"set format \"%g\"":   "format",
+		"set logscale y2 10":  "logscale y2",
+	} {
+		if s, _ := parseSetting(cmd); s.name != want {
+			t.Errorf("parseSetting(%q) = %q, want %q", cmd, s.name, want)
+		}
+	}
+}
+
+func TestTimeSeriesBinary(t *testing.T) {
+	plot, _ := NewPlotWithOptions(2, WithBackend(NewRecordingBackend()), WithTerminal("dumb"),
+		WithTempDir(t.TempDir()), WithTransport(TransportBinary))
+	times := []time.Time{time.Unix(1, 0), time.Unix(2, 0)}
+	plot.AddTimeSeries("cpu", "lines", times, []float64{1, 2})
+	if source := plot.PointGroup["cpu"].source; strings.Contains(source, "binary") {
+		t.Errorf("Expected time series to be written as text, got %s", source)
+	}
+}
@@ -0,0 +1,119 @@
+package glot
+
+import (
+	"errors"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// A map between os files and file names
+type tmpfilesDb map[string]*os.File
+
+// createTmpfile creates a data file in the plot's own temporary
+// directory, which is made on first use inside the configured temp dir.
+func (plot *Plot) createTmpfile() (*os.File, error) {
+	if plot.dataDir == "" {
+		dir, err := os.MkdirTemp(plot.tempDir, gGnuplotPrefix)
+		if err != nil {
+			return nil, err
+		}
+		plot.dataDir = dir
+		if plot.signalCleanup {
+			registerCleanup(dir, plot)
+		}
+	}
+	f, err := os.CreateTemp(plot.dataDir, gGnuplotPrefix)
+	if err != nil {
+		return nil, err
+	}
+	plot.tmpfiles[f.Name()] = f
+	return f, nil
+}
+
+// removeTmpfile deletes a data file of the plot.
+func (plot *Plot) removeTmpfile(fname string) error {
+	if _, ok := plot.tmpfiles[fname]; !ok {
+		return nil
+	}
+	delete(plot.tmpfiles, fname)
+	if err := os.Remove(fname); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// removeTmpfiles deletes all data files of the plot.
+func (plot *Plot) removeTmpfiles() error {
+	var errs []error
+	for fname := range plot.tmpfiles {
+		errs = append(errs, plot.removeTmpfile(fname))
+	}
+	return errors.Join(errs...)
+}
+
+// removeDataDir deletes the plot's temporary directory with everything
+// in it.
+func (plot *Plot) removeDataDir() error {
+	if plot.dataDir == "" {
+		return nil
+	}
+	plot.tmpfiles = make(tmpfilesDb)
+	unregisterCleanup(plot.dataDir)
+	err := os.RemoveAll(plot.dataDir)
+	plot.dataDir = ""
+	return err
+}
+
+// The temporary directories of plots made WithSignalCleanup and the
+// plots they belong to, which are removed and closed when the program
+// receives SIGINT or SIGTERM.
+var gCleanup = struct {
+	sync.Mutex
+	dirs    map[string]*Plot
+	signals chan os.Signal
+}{dirs: make(map[string]*Plot)}
+
+func registerCleanup(dir string, plot *Plot) {
+	gCleanup.Lock()
+	defer gCleanup.Unlock()
+	gCleanup.dirs[dir] = plot
+	if gCleanup.signals == nil {
+		gCleanup.signals = make(chan os.Signal, 1)
+		signal.Notify(gCleanup.signals, os.Interrupt, syscall.SIGTERM)
+		go cleanupOnSignal(gCleanup.signals)
+	}
+}
+
+func unregisterCleanup(dir string) {
+	gCleanup.Lock()
+	defer gCleanup.Unlock()
+	delete(gCleanup.dirs, dir)
+}
+
+// cleanupOnSignal waits for a signal, removes the registered directories
+// and exits with the status of a program killed by the signal. The
+// program's own handlers of the signal don't run to completion.
+func cleanupOnSignal(signals chan os.Signal) {
+	sig := <-signals
+	cleanup()
+	code := 1
+	if s, ok := sig.(syscall.Signal); ok {
+		code = 128 + int(s)
+	}
+	os.Exit(code)
+}
+
+// cleanup removes the registered directories and marks their plots as
+// closed, so a plot used until the program exits fails with
+// ErrNotRunning instead of plotting removed files.
+func cleanup() {
+	gCleanup.Lock()
+	defer gCleanup.Unlock()
+	for dir, plot := range gCleanup.dirs {
+		plot.closed.Store(true)
+		os.RemoveAll(dir)
+	}
+	gCleanup.dirs = make(map[string]*Plot)
+}
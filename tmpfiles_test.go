@@ -0,0 +1,102 @@
+package glot
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// countFiles returns the number of regular files below dir.
+func countFiles(t *testing.T, dir string) int {
+	n := 0
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			n++
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func TestTmpfilesRemoved(t *testing.T) {
+	dir := t.TempDir()
+	plot, err := NewPlotWithOptions(2, WithBackend(NewRecordingBackend()), WithTempDir(dir), WithTerminal("dumb"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	plot.AddPointGroup("Sample1", "points", []float64{1, 2, 3})
+	plot.AddPointGroup("Sample2", "lines", [][]float64{{1, 2, 3}, {4, 5, 6}})
+	plot.AddPointGroup("Sample3", "points", []int{4, 5, 6})
+	if n := countFiles(t, dir); n != 3 {
+		t.Fatalf("Expected 3 data files, found %d", n)
+	}
+
+	plot.RemovePointGroup("Sample3")
+	if n := countFiles(t, dir); n != 2 {
+		t.Errorf("Expected 2 data files after RemovePointGroup, found %d", n)
+	}
+
+	plot.ResetPlot()
+	if n := countFiles(t, dir); n != 0 {
+		t.Errorf("Expected no data files after ResetPlot, found %d", n)
+	}
+
+	plot.AddPointGroup("Sample1", "points", []float64{1, 2, 3})
+	if err := plot.Close(); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := filepath.Glob(filepath.Join(dir, "*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("Expected no files to remain after Close, found %v", entries)
+	}
+}
+
+func TestSignalCleanupRegistration(t *testing.T) {
+	dir := t.TempDir()
+	plot, _ := NewPlotWithOptions(2, WithBackend(NewRecordingBackend()), WithTempDir(dir),
+		WithTerminal("dumb"), WithSignalCleanup())
+	plot.AddPointGroup("Sample1", "points", []float64{1, 2, 3})
+
+	gCleanup.Lock()
+	registered := gCleanup.dirs[plot.dataDir]
+	gCleanup.Unlock()
+	if registered != plot {
+		t.Error("Expected the data directory to be registered for cleanup on signals")
+	}
+	dataDir := plot.dataDir
+	plot.Close()
+	gCleanup.Lock()
+	_, ok := gCleanup.dirs[dataDir]
+	gCleanup.Unlock()
+	if ok {
+		t.Error("Expected Close to unregister the data directory")
+	}
+}
+
+func TestSignalCleanup(t *testing.T) {
+	plot, _ := NewPlotWithOptions(2, WithBackend(NewRecordingBackend()), WithTempDir(t.TempDir()),
+		WithTerminal("dumb"), WithSignalCleanup())
+	plot.AddPointGroup("Sample1", "points", []float64{1, 2, 3})
+	dataDir := plot.dataDir
+	cleanup()
+	if _, err := os.Stat(dataDir); !os.IsNotExist(err) {
+		t.Errorf("Expected the data directory to be removed, got %v", err)
+	}
+	if err := plot.AddPointGroup("Sample2", "points", []float64{4, 5, 6}); !errors.Is(err, ErrNotRunning) {
+		t.Errorf("Expected ErrNotRunning after the cleanup, got %v", err)
+	}
+	if err := plot.Cmd("replot"); !errors.Is(err, ErrNotRunning) {
+		t.Errorf("Expected ErrNotRunning after the cleanup, got %v", err)
+	}
+}
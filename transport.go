@@ -0,0 +1,168 @@
+package glot
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+)
+
+// Transport selects how the data of the PointGroups is passed to gnuplot.
+type Transport int
+
+const (
+	// TransportFile writes the data of every PointGroup to a temporary
+	// file that is referenced by its path. This is the default.
+	TransportFile Transport = iota
+	// TransportDatablock streams the data of every PointGroup as a named
+	// datablock (`$G1 << EOD ... EOD`) over gnuplot's stdin. It works on
+	// read-only filesystems and when gnuplot can't access the files of
+	// the calling process.
+	TransportDatablock
+	// TransportBinary writes the data of every PointGroup to a temporary
+	// file of little-endian float64 records, which avoids formatting and
+	// parsing large data sets as text. TransportFile switches to it
+	// automatically above a size threshold, see WithBinaryThreshold.
+	TransportBinary
+)
+
+// defaultBinaryThreshold is the number of values above which
+// TransportFile writes binary files.
+const defaultBinaryThreshold = 1 << 20
+
+func (t Transport) String() string {
+	switch t {
+	case TransportFile:
+		return "file"
+	case TransportDatablock:
+		return "datablock"
+	case TransportBinary:
+		return "binary"
+	}
+	return fmt.Sprintf("Transport(%d)", int(t))
+}
+
+// dataWriter serializes the data of a PointGroup.
+type dataWriter struct {
+	text   func(w *bufio.Writer) error // writes one line per point
+	binary func(w *bufio.Writer) error // writes little-endian float64 records, nil if the data needs text
+	format string                      // gnuplot's binary format of a record
+	using  string                      // using spec for binary data, if any
+	values int                         // total number of values
+}
+
+// dataSource passes the data of a PointGroup to gnuplot unless that
+// was already done and returns the data source to be used in plot
+// commands, i.e. a quoted file name with its modifiers or the name of a
+// datablock.
+func (plot *Plot) dataSource(ctx context.Context, PointGroup *PointGroup, dw dataWriter) (string, error) {
+	if PointGroup.source != "" {
+		return PointGroup.dataSpec(), nil
+	}
+	transport := plot.transport
+	if transport == TransportFile && plot.binaryThreshold > 0 && dw.values > plot.binaryThreshold {
+		transport = TransportBinary
+	}
+	if dw.binary == nil && transport == TransportBinary {
+		transport = TransportFile
+	}
+	switch transport {
+	case TransportDatablock:
+		name := fmt.Sprintf("$%s%d", plot.blockPrefix, plot.nblocks+1)
+		if err := plot.defineDatablock(ctx, name, dw.text); err != nil {
+			return "", err
+		}
+		plot.nblocks++
+		PointGroup.block = name
+		PointGroup.source = name
+	case TransportBinary:
+		fname, err := plot.writeTmpfile(dw.binary)
+		if err != nil {
+			return "", err
+		}
+		PointGroup.file = fname
+		PointGroup.source = fmt.Sprintf("\"%s\" binary format=\"%s\" endian=little", fname, dw.format)
+		PointGroup.using = dw.using
+	default:
+		fname, err := plot.writeTmpfile(dw.text)
+		if err != nil {
+			return "", err
+		}
+		PointGroup.file = fname
+		PointGroup.source = fmt.Sprintf("\"%s\"", fname)
+	}
+	return PointGroup.dataSpec(), nil
+}
+
+// writeTmpfile writes a data file with write and returns its name.
+func (plot *Plot) writeTmpfile(write func(w *bufio.Writer) error) (string, error) {
+	f, err := plot.createTmpfile()
+	if err != nil {
+		return "", err
+	}
+	w := bufio.NewWriter(f)
+	err = write(w)
+	if err == nil {
+		err = w.Flush()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		plot.removeTmpfile(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+// defineDatablock sends the data written by write to gnuplot as the
+// datablock name.
+func (plot *Plot) defineDatablock(ctx context.Context, name string, write func(w *bufio.Writer) error) error {
+	var buf bytes.Buffer
+	w := bufio.NewWriter(&buf)
+	if err := write(w); err != nil {
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if err := plot.ensureAlive(ctx); err != nil {
+		return err
+	}
+	if plot.logger != nil {
+		plot.logger.Printf("cmd> %s << EOD (%d bytes)\n", name, buf.Len())
+	}
+	if err := plot.backend.Command(ctx, name+" << EOD"); err != nil {
+		return err
+	}
+	if err := plot.backend.Data(ctx, buf.Bytes()); err != nil {
+		return err
+	}
+	if err := plot.backend.Command(ctx, "EOD"); err != nil {
+		return err
+	}
+	_, err := plot.backend.Sync(ctx)
+	if cmdErr, ok := err.(*CommandError); ok && cmdErr.Warning {
+		return nil
+	}
+	return err
+}
+
+// releaseData frees the data of a PointGroup, i.e. removes its file or
+// undefines its datablock.
+func (plot *Plot) releaseData(ctx context.Context, PointGroup *PointGroup) error {
+	var err error
+	if PointGroup.file != "" {
+		err = plot.removeTmpfile(PointGroup.file)
+		PointGroup.file = ""
+	}
+	if PointGroup.block != "" {
+		if uerr := plot.cmd(ctx, "undefine %s", PointGroup.block); err == nil {
+			err = uerr
+		}
+		PointGroup.block = ""
+	}
+	PointGroup.source = ""
+	PointGroup.using = ""
+	return err
+}
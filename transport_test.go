@@ -0,0 +1,139 @@
+package glot
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"slices"
+	"testing"
+)
+
+func TestTransportDatablock(t *testing.T) {
+	dir := t.TempDir()
+	backend := NewRecordingBackend()
+	plot, err := NewPlotWithOptions(2, WithBackend(backend), WithTerminal("dumb"),
+		WithTransport(TransportDatablock), WithTempDir(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	backend.Reset()
+	plot.AddPointGroup("Sample1", "points", []int{3, 4})
+	plot.AddPointGroup("Sample2", "lines", [][]float64{{1, 2}, {5, 6}})
+	plot.RemovePointGroup("Sample1")
+	want := []string{
+		"$G1 << EOD",
+		"3",
+		"4",
+		"EOD",
+		"reset",
+		"plot $G1 title \"Sample1\"  with points",
+		"$G2 << EOD",
+		"1 5",
+		"2 6",
+		"EOD",
+		"reset",
+		"plot $G1 title \"Sample1\"  with points, $G2 title \"Sample2\"  with lines",
+		"undefine $G1",
+		"reset",
+		"plot $G2 title \"Sample2\"  with lines",
+	}
+	if got := backend.Commands(); !slices.Equal(got, want) {
+		t.Errorf("Wrong commands:\n got %q\nwant %q", got, want)
+	}
+	if n := countFiles(t, dir); n != 0 {
+		t.Errorf("Expected no data files with datablocks, found %d", n)
+	}
+}
+
+func TestTransportBinary(t *testing.T) {
+	dir := t.TempDir()
+	backend := NewRecordingBackend()
+	plot, _ := NewPlotWithOptions(2, WithBackend(backend), WithTerminal("dumb"),
+		WithTempDir(dir), WithBinaryThreshold(4))
+	backend.Reset()
+	plot.AddPointGroup("Small", "points", []float64{1, 2, 3})
+	plot.AddPointGroup("Large", "lines", [][]float64{{1, 2, 3}, {4, 5, 6}})
+	small := plot.PointGroup["Small"]
+	large := plot.PointGroup["Large"]
+	want := []string{
+		"reset",
+		fmt.Sprintf("plot \"%s\" title \"Small\"  with points", small.file),
+		"reset",
+		fmt.Sprintf("plot \"%s\" title \"Small\"  with points, \"%s\" binary format=\"%%float64%%float64\" endian=little title \"Large\"  with lines",
+			small.file, large.file),
+	}
+	if got := backend.Commands(); !slices.Equal(got, want) {
+		t.Errorf("Wrong commands:\n got %q\nwant %q", got, want)
+	}
+	data, err := os.ReadFile(large.file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 6*8 {
+		t.Errorf("Expected 6 float64 values in the binary file, got %d bytes", len(data))
+	}
+	if v := math.Float64frombits(binary.LittleEndian.Uint64(data[8:])); v != 4 {
+		t.Errorf("Expected the file to be written row by row, second value is %v", v)
+	}
+
+	plot, _ = NewPlotWithOptions(2, WithBackend(backend), WithTerminal("dumb"),
+		WithTempDir(dir), WithTransport(TransportBinary))
+	backend.Reset()
+	plot.AddPointGroup("Small", "points", []float64{1, 2, 3})
+	want = []string{
+		"reset",
+		fmt.Sprintf("plot \"%s\" binary format=\"%%float64\" endian=little using 0:1 title \"Small\"  with points",
+			plot.PointGroup["Small"].file),
+	}
+	if got := backend.Commands(); !slices.Equal(got, want) {
+		t.Errorf("Wrong commands:\n got %q\nwant %q", got, want)
+	}
+}
+
+func benchmarkAddPointGroup(b *testing.B, transport Transport, data any) {
+	backend := NewRecordingBackend()
+	plot, _ := NewPlotWithOptions(2, WithBackend(backend), WithTerminal("dumb"),
+		WithTempDir(b.TempDir()), WithTransport(transport), WithBinaryThreshold(0))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for b.Loop() {
+		if err := plot.AddPointGroup("Sample", "lines", data); err != nil {
+			b.Fatal(err)
+		}
+		plot.RemovePointGroup("Sample")
+		backend.Reset()
+	}
+}
+
+func benchmarkData1D(n int) []float64 {
+	data := make([]float64, n)
+	for i := range data {
+		data[i] = math.Sin(float64(i) / 100)
+	}
+	return data
+}
+
+func benchmarkDataND(n int) [][]float64 {
+	x := make([]float64, n)
+	for i := range x {
+		x[i] = float64(i) / 1000
+	}
+	return [][]float64{x, benchmarkData1D(n)}
+}
+
+func BenchmarkAddPointGroup1DText(b *testing.B) {
+	benchmarkAddPointGroup(b, TransportFile, benchmarkData1D(100000))
+}
+
+func BenchmarkAddPointGroup1DBinary(b *testing.B) {
+	benchmarkAddPointGroup(b, TransportBinary, benchmarkData1D(100000))
+}
+
+func BenchmarkAddPointGroupNDText(b *testing.B) {
+	benchmarkAddPointGroup(b, TransportFile, benchmarkDataND(100000))
+}
+
+func BenchmarkAddPointGroupNDBinary(b *testing.B) {
+	benchmarkAddPointGroup(b, TransportBinary, benchmarkDataND(100000))
+}